@@ -0,0 +1,76 @@
+package fastcdc
+
+import "math"
+
+const (
+	// adaptiveWindow is the number of chunks WithAdaptiveTarget averages before
+	// each adjustment.
+	adaptiveWindow = 64
+
+	// adaptiveTolerance is the divisor of targetSize giving how far the average
+	// may drift from it before WithAdaptiveTarget adjusts the masks (25%).
+	adaptiveTolerance = 4
+)
+
+// adapt records a chunk length for WithAdaptiveTarget and, once per window,
+// removes a mask bit if the chunks averaged more than targetSize by the
+// tolerance, halving the expected distance between matches, or adds one if
+// they averaged less.
+func (c *Chunker) adapt(length uint32) {
+	c.adaptSum += uint64(length)
+
+	c.adaptCount++
+	if c.adaptCount < adaptiveWindow {
+		return
+	}
+
+	mean := c.adaptSum / adaptiveWindow
+	target := uint64(c.core.TargetSize())
+	c.adaptSum, c.adaptCount = 0, 0
+
+	switch {
+	case mean > target+target/adaptiveTolerance:
+		c.core.adjustMasks(c.cfg.boundary, -1)
+		c.adapted = true
+	case mean < target-target/adaptiveTolerance:
+		c.core.adjustMasks(c.cfg.boundary, 1)
+		c.adapted = true
+	}
+}
+
+// adjustMasks removes the lowest set bit of both masks if delta is negative,
+// or sets their lowest unset bit otherwise, recomputing the values they must
+// match from boundary. Masks that would lose their last bit, or are full, are
+// left unchanged.
+func (c *ChunkerCore) adjustMasks(boundary uint64, delta int) {
+	if delta < 0 {
+		if c.maskL&(c.maskL-1) == 0 {
+			return
+		}
+
+		c.maskL &= c.maskL - 1
+		c.maskS &= c.maskS - 1 // Zero stays zero
+
+		for i := range c.nRegions {
+			c.regions[i].mask &= c.regions[i].mask - 1
+		}
+	} else {
+		if c.maskL == math.MaxUint64 {
+			return
+		}
+
+		c.maskL |= c.maskL + 1
+		c.maskS |= c.maskS + 1
+
+		for i := range c.nRegions {
+			c.regions[i].mask |= c.regions[i].mask + 1 // Full stays full
+		}
+	}
+
+	c.matchS = boundary & c.maskS
+	c.matchL = boundary & c.maskL
+
+	for i := range c.nRegions {
+		c.regions[i].match = boundary & c.regions[i].mask
+	}
+}
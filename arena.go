@@ -0,0 +1,116 @@
+package fastcdc
+
+// DefaultArenaBlockSize is the size of the backing slices of a ChunkArena
+// created without a block size.
+const DefaultArenaBlockSize = 4 * 1024 * 1024
+
+// ChunkArena holds copies of chunk data in a few large backing slices, handing
+// out sub-slices of them, so that collecting every chunk of an input costs an
+// allocation per block rather than per chunk. The copies stay valid until
+// Reset, which keeps the blocks for reuse. A chunk larger than the block size
+// gets a block of its own.
+//
+// The zero value is ready to use with DefaultArenaBlockSize. A ChunkArena must
+// not be used by several goroutines at once.
+type ChunkArena struct {
+	blocks    [][]byte // Backing slices, in order of use
+	next      int      // Index of the block used after free
+	free      []byte   // Unused part of the current block
+	blockSize int
+
+	// Reused space the chunker sums Chunk.Digest and Chunk.ID into before
+	// they are copied into the blocks
+	digest, id []byte
+}
+
+// NewChunkArena returns a ChunkArena allocating blocks of blockSize bytes, or
+// DefaultArenaBlockSize if blockSize is not positive. Blocks of a few times the
+// target size of the chunks waste little space at their end.
+func NewChunkArena(blockSize int) *ChunkArena {
+	return &ChunkArena{blockSize: blockSize}
+}
+
+// Copy returns a copy of data held by the arena.
+func (a *ChunkArena) Copy(data []byte) []byte {
+	dst := a.alloc(len(data))
+	copy(dst, data)
+
+	return dst
+}
+
+// Reset makes the arena reuse its blocks from the start. Data returned before
+// is overwritten by later copies, so it must no longer be used.
+func (a *ChunkArena) Reset() {
+	a.next = 0
+	a.free = nil
+}
+
+// alloc returns n bytes of the arena, with a capacity of n.
+func (a *ChunkArena) alloc(n int) []byte {
+	if n > len(a.free) {
+		a.grow(n)
+	}
+
+	dst := a.free[:n:n]
+	a.free = a.free[n:]
+
+	return dst
+}
+
+// grow makes free a block of at least n bytes, the next kept one that is large
+// enough or a new one.
+func (a *ChunkArena) grow(n int) {
+	for a.next < len(a.blocks) {
+		block := a.blocks[a.next]
+		a.next++
+
+		if len(block) >= n {
+			a.free = block
+
+			return
+		}
+	}
+
+	size := a.blockSize
+	if size <= 0 {
+		size = DefaultArenaBlockSize
+	}
+
+	block := make([]byte, max(size, n))
+	a.blocks = append(a.blocks, block)
+	a.next = len(a.blocks)
+	a.free = block
+}
+
+// NextInto is like Next but copies Chunk.Data, and Chunk.Digest and Chunk.ID
+// with WithChunkDigest and WithChunkID, into a, so that they stay valid after
+// later calls, until a is reset. It avoids the allocation per chunk of keeping
+// copies, for callers that retain every chunk, such as to build an in-memory
+// index. Nothing is copied into a when an error is returned. With
+// WithGuardedData the copy is in Data and Guarded is left empty. WithCopyData
+// is not needed and would copy every chunk twice.
+func (c *Chunker) NextInto(a *ChunkArena) (Chunk, error) {
+	chunk, err := c.next(a.digest[:0], a.id[:0])
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	// Keep the space the sums were appended to, and copy them out of it
+	if chunk.Digest != nil {
+		a.digest = chunk.Digest
+		chunk.Digest = a.Copy(chunk.Digest)
+	}
+
+	if chunk.ID != nil {
+		a.id = chunk.ID
+		chunk.ID = a.Copy(chunk.ID)
+	}
+
+	if c.cfg.guardedData {
+		chunk.Data, chunk.Guarded = a.Copy(chunk.Guarded.Bytes()), ChunkData{}
+	} else {
+		chunk.Data = a.Copy(chunk.Data)
+	}
+
+	return chunk, nil
+}
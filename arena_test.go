@@ -0,0 +1,126 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestNextInto tests that chunks copied into an arena stay valid while more
+// are read, across blocks and resets.
+func TestNextInto(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024, 1339)
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(4 * 1024),
+		fastcdc.WithTargetSize(16 * 1024),
+		fastcdc.WithMaxSize(64 * 1024),
+		fastcdc.WithChunkDigest(sha256.New),
+		fastcdc.WithChunkID([]byte("ns")),
+	}
+
+	// Blocks smaller than maxSize make some chunks take a block of their own
+	for _, arena := range []*fastcdc.ChunkArena{new(fastcdc.ChunkArena), fastcdc.NewChunkArena(40 * 1024)} {
+		c := mustChunker(t, bytes.NewReader(data), opts...)
+
+		for round := range 2 {
+			var chunks []fastcdc.Chunk
+
+			for {
+				chunk, err := c.NextInto(arena)
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				chunks = append(chunks, chunk)
+			}
+
+			for _, chunk := range chunks {
+				sum := sha256.Sum256(chunk.Data)
+				id := sha256.Sum256(append([]byte("ns"), chunk.Data...))
+
+				if !bytes.Equal(chunk.Data, data[chunk.Offset:chunk.Offset+uint64(chunk.Length)]) ||
+					!bytes.Equal(chunk.Digest, sum[:]) || !bytes.Equal(chunk.ID, id[:]) {
+					t.Fatalf("round %d: chunk at %d was overwritten", round, chunk.Offset)
+				}
+			}
+
+			arena.Reset()
+			c.Reset(bytes.NewReader(data))
+		}
+	}
+
+	// Guarded data is copied into Data
+	c := mustChunker(t, bytes.NewReader(data), fastcdc.WithGuardedData(true))
+
+	chunk, err := c.NextInto(new(fastcdc.ChunkArena))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Next(); err != nil || !bytes.Equal(chunk.Data, data[:chunk.Length]) || chunk.Guarded.Valid() {
+		t.Errorf("guarded chunk not copied: %v", err)
+	}
+}
+
+// TestNextIntoAllocs tests that a reset arena chunks an input again without
+// allocating.
+//
+//nolint:paralleltest // AllocsPerRun cannot be used in parallel tests
+func TestNextIntoAllocs(t *testing.T) {
+	data := randBytes(1024*1024, 1339)
+	c := mustChunker(t, bytes.NewReader(data), fastcdc.WithMinSize(4*1024), fastcdc.WithTargetSize(16*1024))
+	arena := fastcdc.NewChunkArena(256 * 1024)
+	r := bytes.NewReader(data)
+
+	allocs := testing.AllocsPerRun(5, func() {
+		arena.Reset()
+		r.Reset(data)
+		c.Reset(r)
+
+		for {
+			if _, err := c.NextInto(arena); err != nil {
+				break
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("NextInto allocated %.1f times per input, want 0", allocs)
+	}
+
+	// Digests and IDs are summed into reused space, and calls at EOF take no
+	// arena space, so a small arena gets no new blocks
+	c = mustChunker(t, bytes.NewReader(data), fastcdc.WithMinSize(4*1024), fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithChunkDigest(sha256.New), fastcdc.WithChunkID([]byte("ns")))
+	arena = fastcdc.NewChunkArena(256 * 1024)
+
+	allocs = testing.AllocsPerRun(5, func() {
+		arena.Reset()
+		r.Reset(data)
+		c.Reset(r)
+
+		for {
+			if _, err := c.NextInto(arena); err != nil {
+				break
+			}
+		}
+
+		for range 10000 {
+			if _, err := c.NextInto(arena); !errors.Is(err, io.EOF) {
+				t.Fatal(err)
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("NextInto with digests allocated %.1f times per input, want 0", allocs)
+	}
+}
@@ -1,9 +1,10 @@
 package benchmarks
 
 import (
+	"bufio"
 	"bytes"
-	"crypto/rand"
 	"io"
+	"runtime"
 	"testing"
 
 	fastcdc "github.com/kalbasit/fastcdc"
@@ -18,10 +19,7 @@ func BenchmarkChunkerNext(b *testing.B) {
 	}
 
 	for _, size := range sizes {
-		data := make([]byte, size)
-		if _, err := rand.Read(data); err != nil {
-			b.Fatal(err)
-		}
+		data := fastcdc.TestData(1, size)
 
 		b.Run(formatSize(size), func(b *testing.B) {
 			b.SetBytes(int64(size))
@@ -43,6 +41,112 @@ func BenchmarkChunkerNext(b *testing.B) {
 	}
 }
 
+// BenchmarkChunkerFromBytes benchmarks the Next() API over in-memory data.
+func BenchmarkChunkerFromBytes(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
+	b.SetBytes(int64(len(data)))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		chunker, _ := fastcdc.NewChunkerFromBytes(data, fastcdc.WithTargetSize(64*1024))
+		for {
+			_, err := chunker.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+// BenchmarkChunkerCopyData compares Next() with and without WithCopyData().
+func BenchmarkChunkerCopyData(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
+	for _, tc := range []struct {
+		name string
+		opts []fastcdc.Option
+	}{
+		{"ZeroCopy", nil},
+		{"CopyData", []fastcdc.Option{fastcdc.WithCopyData()}},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			opts := append([]fastcdc.Option{fastcdc.WithTargetSize(64 * 1024)}, tc.opts...)
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				chunker, _ := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+				for {
+					_, err := chunker.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
+// BenchmarkChunkerBufferSize compares a buffer that barely holds a maximum-size
+// chunk, refilled for nearly every chunk, with RecommendedBufferSize.
+func BenchmarkChunkerBufferSize(b *testing.B) {
+	data := fastcdc.TestData(1, 32*1024*1024) // 32 MiB
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(64 * 1024),
+		fastcdc.WithTargetSize(256 * 1024),
+		fastcdc.WithMaxSize(1024 * 1024),
+	}
+
+	recommended, err := fastcdc.RecommendedBufferSize(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name string
+		size int
+	}{
+		{"MaxSizePlusOne", 1024*1024 + 1},
+		{"Recommended", recommended},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			opts := append(opts[:len(opts):len(opts)], fastcdc.WithBufferSize(tc.size))
+
+			var reads int
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				r := &countingReader{r: bytes.NewReader(data)}
+				chunker, _ := fastcdc.NewChunker(r, opts...)
+				for {
+					_, err := chunker.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+				reads += r.reads
+			}
+
+			b.ReportMetric(float64(reads)/float64(b.N), "reads/op")
+		})
+	}
+}
+
 // BenchmarkChunkerCoreFindBoundary benchmarks the zero-allocation FindBoundary() API.
 func BenchmarkChunkerCoreFindBoundary(b *testing.B) {
 	sizes := []int{
@@ -52,10 +156,7 @@ func BenchmarkChunkerCoreFindBoundary(b *testing.B) {
 	}
 
 	for _, size := range sizes {
-		data := make([]byte, size)
-		if _, err := rand.Read(data); err != nil {
-			b.Fatal(err)
-		}
+		data := fastcdc.TestData(1, size)
 
 		b.Run(formatSize(size), func(b *testing.B) {
 			// Create core once outside the loop for true zero-allocation benchmark
@@ -81,12 +182,82 @@ func BenchmarkChunkerCoreFindBoundary(b *testing.B) {
 	}
 }
 
+// BenchmarkChunkerCoreFindAllBoundaries benchmarks scanning a whole buffer with FindAllBoundaries().
+func BenchmarkChunkerCoreFindAllBoundaries(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
+	core, _ := fastcdc.NewChunkerCore(fastcdc.WithTargetSize(64 * 1024))
+	onChunk := func(_, _ int, _ uint64) {}
+
+	b.SetBytes(int64(len(data)))
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		core.Reset()
+		core.FindAllBoundaries(data, onChunk)
+	}
+}
+
+// BenchmarkChunkerCoreSmallInputs benchmarks FindBoundary() on many inputs
+// shorter than minSize, as when chunking a tree of small source files.
+func BenchmarkChunkerCoreSmallInputs(b *testing.B) {
+	const count = 1000
+
+	// Sizes spread over [1, minSize], slicing one buffer
+	data := fastcdc.TestData(1, count*fastcdc.DefaultMinSize)
+	sizes := fastcdc.TestData(2, 2*count)
+	inputs := make([][]byte, count)
+	total := 0
+
+	for i := range inputs {
+		size := 1 + (int(sizes[2*i])<<8|int(sizes[2*i+1]))%fastcdc.DefaultMinSize
+		inputs[i] = data[i*fastcdc.DefaultMinSize:][:size]
+		total += size
+	}
+
+	core, _ := fastcdc.NewChunkerCore()
+
+	b.SetBytes(int64(total))
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		for _, input := range inputs {
+			core.Reset()
+			core.FindBoundary(input)
+		}
+	}
+}
+
+// BenchmarkChunkerCoreRollingHash compares the Gear and Rabin rolling hashes.
+func BenchmarkChunkerCoreRollingHash(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
+	for _, tc := range []struct {
+		name string
+		kind fastcdc.RollingHash
+	}{
+		{"Gear", fastcdc.HashGear},
+		{"Rabin", fastcdc.HashRabin},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			core, _ := fastcdc.NewChunkerCore(fastcdc.WithTargetSize(64*1024), fastcdc.WithRollingHash(tc.kind))
+			onChunk := func(_, _ int, _ uint64) {}
+
+			b.SetBytes(int64(len(data)))
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				core.Reset()
+				core.FindAllBoundaries(data, onChunk)
+			}
+		})
+	}
+}
+
 // BenchmarkChunkerPool benchmarks pool performance.
 func BenchmarkChunkerPool(b *testing.B) {
-	data := make([]byte, 10*1024*1024) // 10 MiB
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
 
 	pool, err := fastcdc.NewChunkerPool(fastcdc.WithTargetSize(64 * 1024))
 	if err != nil {
@@ -111,13 +282,64 @@ func BenchmarkChunkerPool(b *testing.B) {
 	}
 }
 
-// BenchmarkChunkerConcurrent benchmarks concurrent chunking.
-func BenchmarkChunkerConcurrent(b *testing.B) {
-	data := make([]byte, 10*1024*1024) // 10 MiB
-	if _, err := rand.Read(data); err != nil {
+// BenchmarkChunkerPoolParallel compares ChunkerPool with ShardedChunkerPool
+// under contention: goroutines on every P chunk small inputs, so that Get and
+// Put weigh on each operation. Run with -cpu to vary GOMAXPROCS.
+func BenchmarkChunkerPoolParallel(b *testing.B) {
+	data := fastcdc.TestData(1, 64*1024) // 64 KiB
+	opts := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(32 * 1024)}
+
+	// pool is the part of ChunkerPool and ShardedChunkerPool the benchmark uses
+	type pool interface {
+		Get(r io.Reader) (*fastcdc.Chunker, error)
+		Put(c *fastcdc.Chunker)
+	}
+
+	plain, err := fastcdc.NewChunkerPool(opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	sharded, err := fastcdc.NewShardedChunkerPool(runtime.GOMAXPROCS(0), opts...)
+	if err != nil {
 		b.Fatal(err)
 	}
 
+	for _, tc := range []struct {
+		name string
+		pool pool
+	}{
+		{"Pool", plain},
+		{"Sharded", sharded},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			b.RunParallel(func(pb *testing.PB) {
+				for pb.Next() {
+					chunker, _ := tc.pool.Get(bytes.NewReader(data))
+					for {
+						_, err := chunker.Next()
+						if err == io.EOF {
+							break
+						}
+						if err != nil {
+							b.Fatal(err)
+						}
+					}
+					tc.pool.Put(chunker)
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkChunkerConcurrent benchmarks concurrent chunking.
+func BenchmarkChunkerConcurrent(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
 	b.SetBytes(int64(len(data)))
 	b.ResetTimer()
 
@@ -139,10 +361,7 @@ func BenchmarkChunkerConcurrent(b *testing.B) {
 
 // BenchmarkChunkerTargetSizes benchmarks different target sizes.
 func BenchmarkChunkerTargetSizes(b *testing.B) {
-	data := make([]byte, 10*1024*1024) // 10 MiB
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
 
 	tests := []struct {
 		targetSize uint32
@@ -188,10 +407,7 @@ func BenchmarkChunkerTargetSizes(b *testing.B) {
 
 // BenchmarkChunkerNormalizationLevels benchmarks different normalization levels.
 func BenchmarkChunkerNormalizationLevels(b *testing.B) {
-	data := make([]byte, 10*1024*1024) // 10 MiB
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
 
 	levels := []uint8{0, 1, 2, 3, 4}
 
@@ -231,9 +447,7 @@ func BenchmarkChunkerDataTypes(b *testing.B) {
 		{
 			name: "Random",
 			data: func() []byte {
-				d := make([]byte, size)
-				rand.Read(d)
-				return d
+				return fastcdc.TestData(1, size)
 			}(),
 		},
 		{
@@ -273,8 +487,62 @@ func BenchmarkChunkerDataTypes(b *testing.B) {
 	}
 }
 
+// BenchmarkChunkerBufioReader benchmarks chunking through a *bufio.Reader,
+// with and without peeking directly into the bufio.Reader's buffer.
+func BenchmarkChunkerBufioReader(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+
+	tests := []struct {
+		name string
+		wrap func(r *bufio.Reader) io.Reader
+	}{
+		{
+			name: "Peek",
+			wrap: func(r *bufio.Reader) io.Reader { return r },
+		},
+		{
+			name: "DoubleBuffered",
+			// Hide the concrete type so the chunker uses its own buffer
+			wrap: func(r *bufio.Reader) io.Reader { return struct{ io.Reader }{r} },
+		},
+	}
+
+	for _, tt := range tests {
+		b.Run(tt.name, func(b *testing.B) {
+			b.SetBytes(int64(len(data)))
+			b.ReportAllocs()
+			b.ResetTimer()
+
+			for i := 0; i < b.N; i++ {
+				br := bufio.NewReaderSize(bytes.NewReader(data), 512*1024)
+				chunker, _ := fastcdc.NewChunker(tt.wrap(br), fastcdc.WithTargetSize(64*1024))
+				for {
+					_, err := chunker.Next()
+					if err == io.EOF {
+						break
+					}
+					if err != nil {
+						b.Fatal(err)
+					}
+				}
+			}
+		})
+	}
+}
+
 // Helper functions
 
+// countingReader counts the calls to Read.
+type countingReader struct {
+	r     io.Reader
+	reads int
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	c.reads++
+	return c.r.Read(p)
+}
+
 func formatSize(size int) string {
 	const (
 		KiB = 1024
@@ -312,3 +580,98 @@ func itoa(n int) string {
 	}
 	return string(buf[i+1:])
 }
+
+// BenchmarkChunkerCoreUnroll compares the unrolled and plain Gear loops
+// (WithUnroll) over a range of chunk sizes, with minSize = maxSize/4.
+func BenchmarkChunkerCoreUnroll(b *testing.B) {
+	data := fastcdc.TestData(1, 4*1024*1024) // 4 MiB
+
+	for _, maxSize := range []uint32{256, 1024, 2048, 4096, 8192, 256 * 1024} {
+		for _, tc := range []struct {
+			name   string
+			unroll bool
+		}{
+			{"Plain", false},
+			{"Unrolled", true},
+		} {
+			b.Run("Max"+itoa(int(maxSize))+"/"+tc.name, func(b *testing.B) {
+				core, err := fastcdc.NewChunkerCore(fastcdc.WithMinSize(maxSize/4), fastcdc.WithTargetSize(maxSize/2),
+					fastcdc.WithMaxSize(maxSize), fastcdc.WithUnroll(tc.unroll))
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				onChunk := func(_, _ int, _ uint64) {}
+
+				b.SetBytes(int64(len(data)))
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					core.Reset()
+					core.FindAllBoundaries(data, onChunk)
+				}
+			})
+		}
+	}
+}
+
+// BenchmarkChunkerRetain compares keeping a copy of every chunk's data with
+// bytes.Clone and with NextInto and a reused ChunkArena.
+func BenchmarkChunkerRetain(b *testing.B) {
+	data := fastcdc.TestData(1, 10*1024*1024) // 10 MiB
+	opts := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(32 * 1024)}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	chunks := make([][]byte, 0, 2048)
+
+	b.Run("Clone", func(b *testing.B) {
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			chunker.Reset(bytes.NewReader(data))
+			chunks = chunks[:0]
+
+			for {
+				chunk, err := chunker.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				chunks = append(chunks, bytes.Clone(chunk.Data))
+			}
+		}
+	})
+
+	b.Run("Arena", func(b *testing.B) {
+		arena := fastcdc.NewChunkArena(1024 * 1024)
+
+		b.SetBytes(int64(len(data)))
+		b.ReportAllocs()
+
+		for i := 0; i < b.N; i++ {
+			chunker.Reset(bytes.NewReader(data))
+			chunks = chunks[:0]
+			arena.Reset()
+
+			for {
+				chunk, err := chunker.NextInto(arena)
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					b.Fatal(err)
+				}
+
+				chunks = append(chunks, chunk.Data)
+			}
+		}
+	})
+}
@@ -2,7 +2,6 @@ package benchmarks
 
 import (
 	"bytes"
-	"crypto/rand"
 	"io"
 	"testing"
 
@@ -20,10 +19,7 @@ const (
 
 // BenchmarkComparison_Kalbasit benchmarks kalbasit/fastcdc-go (this library)
 func BenchmarkComparison_Kalbasit(b *testing.B) {
-	data := make([]byte, benchmarkSize)
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := kalbasit.TestData(1, benchmarkSize)
 
 	b.SetBytes(benchmarkSize)
 	b.ResetTimer()
@@ -49,10 +45,7 @@ func BenchmarkComparison_Kalbasit(b *testing.B) {
 
 // BenchmarkComparison_Kalbasit_NoNorm benchmarks kalbasit/fastcdc-go without normalization
 func BenchmarkComparison_Kalbasit_NoNorm(b *testing.B) {
-	data := make([]byte, benchmarkSize)
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := kalbasit.TestData(1, benchmarkSize)
 
 	b.SetBytes(benchmarkSize)
 	b.ResetTimer()
@@ -79,10 +72,7 @@ func BenchmarkComparison_Kalbasit_NoNorm(b *testing.B) {
 
 // BenchmarkComparison_Jotfs benchmarks jotfs/fastcdc-go
 func BenchmarkComparison_Jotfs(b *testing.B) {
-	data := make([]byte, benchmarkSize)
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := kalbasit.TestData(1, benchmarkSize)
 
 	b.SetBytes(benchmarkSize)
 	b.ResetTimer()
@@ -110,10 +100,7 @@ func BenchmarkComparison_Jotfs(b *testing.B) {
 
 // BenchmarkComparison_Restic benchmarks restic/chunker
 func BenchmarkComparison_Restic(b *testing.B) {
-	data := make([]byte, benchmarkSize)
-	if _, err := rand.Read(data); err != nil {
-		b.Fatal(err)
-	}
+	data := kalbasit.TestData(1, benchmarkSize)
 
 	// Restic uses a polynomial for initialization
 	pol := restic.Pol(0x3DA3358B4DC173)
@@ -1,6 +1,6 @@
 module github.com/kalbasit/fastcdc/benchmarks
 
-go 1.25.5
+go 1.25.10
 
 require (
 	github.com/jotfs/fastcdc-go v0.2.0
@@ -1,16 +1,117 @@
 package fastcdc
 
 import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
 	"errors"
+	"fmt"
+	"hash"
 	"io"
+	"iter"
+	"math"
 )
 
+var (
+	// ErrNoChunkDigest is returned by NextDigest when no digest is configured with WithChunkDigest.
+	ErrNoChunkDigest = errors.New("no chunk digest configured")
+
+	// ErrNilReader is returned when a Chunker is created or used without a reader.
+	ErrNilReader = errors.New("reader is nil")
+
+	// ErrNegativeSkip is returned by Skip when asked to skip a negative number of bytes.
+	ErrNegativeSkip = errors.New("cannot skip a negative number of bytes")
+)
+
+// ReadError is returned by the streaming API when the underlying reader fails.
+// It records where in the stream the failed read started, which is the offset
+// of the first byte not yet read. errors.Is and errors.As see through it to
+// the reader's error.
+type ReadError struct {
+	Offset uint64 // Absolute offset in the stream of the failed read
+	Err    error  // Error returned by the reader
+}
+
+// Error implements error.
+func (e *ReadError) Error() string {
+	return fmt.Sprintf("read failed at offset %d: %v", e.Offset, e.Err)
+}
+
+// Unwrap returns the reader's error.
+func (e *ReadError) Unwrap() error {
+	return e.Err
+}
+
 // Chunk represents a content-defined chunk with its metadata.
 type Chunk struct {
 	Offset uint64 // Absolute offset in the stream
 	Length uint32 // Chunk size in bytes
-	Hash   uint64 // Gear fingerprint at boundary
-	Data   []byte // Chunk data (points into internal buffer)
+	Hash   uint64 // Gear fingerprint at boundary (zero with WithoutHash)
+	Data   []byte // Chunk data (points into internal buffer unless WithCopyData)
+	Digest []byte // Content digest of Data (WithChunkDigest only)
+	ID     []byte // SHA-256 of the namespace followed by Data (WithChunkID only)
+
+	Hash128 [2]uint64 // Hash followed by a hash of all of Data (WithWideFingerprint only)
+
+	SourceIndex int  // Index of the ResetMulti reader holding the first byte of the chunk
+	Last        bool // The chunk is the final one; the next call to Next returns io.EOF
+	Partial     bool // More of the chunk follows in the next Chunk (WithPartialChunks only)
+
+	Guarded ChunkData // Chunk data with use-after-invalidation checks (WithGuardedData only)
+}
+
+// MatchesStored reports whether the chunk data is byte-for-byte identical to stored.
+//
+// The Gear fingerprint is a weak hash, so dedup stores that find a candidate by
+// hash and length should confirm the match with MatchesStored before treating the
+// chunk as a duplicate. The length is compared first so mismatches short-circuit
+// without touching the data.
+func (c Chunk) MatchesStored(stored []byte) bool {
+	if len(stored) != len(c.Data) {
+		return false
+	}
+
+	return bytes.Equal(c.Data, stored)
+}
+
+// WriteTo writes the chunk data to w, implementing io.WriterTo so chunks can be
+// passed to io.Copy and similar helpers. It returns the number of bytes written.
+func (c Chunk) WriteTo(w io.Writer) (int64, error) {
+	n, err := w.Write(c.Data)
+
+	return int64(n), err
+}
+
+// Reader returns an io.Reader over the chunk data without copying it.
+// The data may be overwritten by the next call to Next, so the reader must be
+// consumed before the Chunker advances.
+func (c Chunk) Reader() io.Reader {
+	return bytes.NewReader(c.Data)
+}
+
+// ChunkData wraps chunk data that is only valid until the Chunker that produced it
+// advances. It is populated instead of Chunk.Data when WithGuardedData is enabled.
+type ChunkData struct {
+	data       []byte
+	generation uint64
+	current    *uint64
+}
+
+// Valid reports whether the data is still valid, i.e. the Chunker has not
+// advanced or been reset since the chunk was returned.
+func (d ChunkData) Valid() bool {
+	return d.current != nil && *d.current == d.generation
+}
+
+// Bytes returns the chunk data.
+// It panics if the Chunker has advanced past the chunk, which would otherwise
+// silently return overwritten data.
+func (d ChunkData) Bytes() []byte {
+	if !d.Valid() {
+		panic("fastcdc: chunk data accessed after the chunker advanced")
+	}
+
+	return d.data
 }
 
 // Chunker provides a convenient streaming API for content-defined chunking.
@@ -18,27 +119,100 @@ type Chunk struct {
 //
 // This API allocates minimally and is suitable for most use cases.
 // For zero-allocation performance-critical code, use ChunkerCore.
+//
+// When the reader is a *bufio.Reader whose buffer can hold more than maxSize bytes,
+// the Chunker finds boundaries directly in the bufio.Reader's buffer using Peek and
+// Discard instead of copying into its own buffer. Chunk boundaries are identical
+// either way.
 type Chunker struct {
-	core   ChunkerCore // Core chunking algorithm (embedded to avoid pointer allocation)
-	reader io.Reader   // Input stream
+	core   ChunkerCore   // Core chunking algorithm (embedded to avoid pointer allocation)
+	reader io.Reader     // Input stream
+	br     *bufio.Reader // Input stream when it is already buffered (nil otherwise)
+	digest hash.Hash     // Chunk content digest (nil unless WithChunkDigest)
+	idHash hash.Hash     // Chunk.ID hash (nil unless WithChunkID)
 
-	buf    []byte // Internal buffer
-	cursor int    // Current position in buffer
-	offset uint64 // Absolute offset in stream
-	eof    bool   // EOF reached
+	cfg        config // Validated configuration
+	buf        []byte // Internal buffer (allocated lazily when br is nil)
+	external   bool   // buf is caller-owned input (NewChunkerFromBytes)
+	cursor     int    // Current position in buffer
+	offset     uint64 // Absolute offset in stream
+	base       uint64 // Added to reported offsets (NewRangeChunker with WithAbsoluteOffsets)
+	eof        bool   // EOF reached
+	generation uint64 // Incremented whenever previously returned data is invalidated
+	stats      sizeStats
+	forcedCuts uint64       // Chunks cut at maxSize since the last Reset
+	shrunk     bool         // Core sizes were lowered by WithMinChunkCount for this input
+	adapted    bool         // Core masks were adjusted by WithAdaptiveTarget
+	adaptSum   uint64       // Total length of the chunks in the current adaptive window
+	adaptCount int          // Chunks in the current adaptive window
+	sources    *multiSource // Concatenated input set by ResetMulti (nil otherwise)
+	progressAt uint64       // Offset from which WithProgress reports again
+	teeErr     error        // Failure of the WithTee writer, returned until Reset
+	shard      int          // ShardedChunkerPool shard the chunker was taken from
+	fork       *Chunker     // Child returned by Fork, reused by later calls
+	seeker     io.Seeker    // Reader rewound by WithRetry (nil when disabled or not seekable)
+	seekStart  int64        // Position of seeker at the start of the stream
+	partLen    uint32       // Bytes of the current chunk returned as parts (WithPartialChunks)
+	brEOF      bool         // br is exhausted and every byte was returned (AtEOF)
+
+	frameAt       uint64 // Offset of the next frame magic found (NewFrameAwareChunker)
+	frameSearched uint64 // Offset up to which no further frame magic starts
 }
 
 // NewChunker creates a new Chunker that reads from the given io.Reader.
+// It returns ErrNilReader if r is nil; use NewConfig to only validate options.
 func NewChunker(r io.Reader, opts ...Option) (*Chunker, error) {
-	// Use stack-allocated config to avoid heap allocation
-	cfg := config{
-		minSize:    DefaultMinSize,
-		targetSize: DefaultTargetSize,
-		maxSize:    DefaultMaxSize,
-		normLevel:  DefaultNormLevel,
-		seed:       0,
-		bufferSize: DefaultBufferSize,
+	if r == nil {
+		return nil, ErrNilReader
 	}
+
+	c, err := newChunker(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.cfg.validateBuffer(); err != nil {
+		return nil, err
+	}
+
+	c.Reset(r)
+
+	return c, nil
+}
+
+// NewChunkerFromConfig creates a new Chunker that reads from r using a Config
+// validated by NewConfig. It cannot fail, since all validation has been done.
+func NewChunkerFromConfig(r io.Reader, cfg Config) *Chunker {
+	c := newChunkerFromConfig(&cfg)
+	c.Reset(r)
+
+	return c
+}
+
+// NewChunkerFromBytes creates a new Chunker over data that is already in memory,
+// such as a loaded or memory-mapped file. It allocates no internal buffer and
+// copies nothing: each Chunk.Data is a sub-slice of data, valid for as long as
+// data is. The chunker never modifies data.
+//
+// Calling Reset switches the chunker to reading from an io.Reader, allocating
+// an internal buffer at that point.
+func NewChunkerFromBytes(data []byte, opts ...Option) (*Chunker, error) {
+	c, err := newChunker(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	c.buf = data
+	c.external = true
+	c.eof = true
+
+	return c, nil
+}
+
+// newChunker creates a Chunker from options without setting up its input.
+func newChunker(opts []Option) (*Chunker, error) {
+	// Use stack-allocated config to avoid heap allocation
+	cfg := defaultConfig()
 	for _, opt := range opts {
 		if err := opt(&cfg); err != nil {
 			return nil, err
@@ -51,70 +225,331 @@ func NewChunker(r io.Reader, opts ...Option) (*Chunker, error) {
 	}
 
 	// Use internal function to avoid duplicate config allocation
-	core := newChunkerCoreWithConfig(&cfg)
+	return newChunkerFromConfig(&Config{cfg: cfg, core: newChunkerCoreWithConfig(&cfg)}), nil
+}
+
+// newChunkerFromConfig creates a Chunker from a validated Config without setting up its input.
+func newChunkerFromConfig(cfg *Config) *Chunker {
+	c := &Chunker{
+		core: cfg.core, // Embed by value to avoid heap allocation
+		cfg:  cfg.cfg,
+	}
 
-	return &Chunker{
-		core:   core, // Embed by value to avoid heap allocation
-		reader: r,
-		buf:    make([]byte, cfg.bufferSize),
-		cursor: cfg.bufferSize, // Start with empty buffer (triggers initial read)
-		offset: 0,
-		eof:    false,
-	}, nil
+	if cfg.cfg.digest != nil {
+		c.digest = cfg.cfg.digest()
+	}
+
+	if cfg.cfg.chunkID != nil {
+		c.idHash = sha256.New()
+	}
+
+	return c
 }
 
 // fillBuffer ensures the buffer has enough data for chunking.
 // It moves unconsumed data to the front and reads more from the reader.
 func (c *Chunker) fillBuffer() error {
+	// Refilling while fewer than lookahead bytes are left, with a buffer of at
+	// least that size, detects EOF before the final chunk is returned
 	n := len(c.buf) - c.cursor
-	if n >= int(c.core.MaxSize()) {
+	if n >= c.cfg.lookahead() || c.eof {
+		// After EOF there is nothing to make room for; data stays in place,
+		// which also keeps in-memory input untouched
 		return nil
 	}
 
+	if c.reader == nil {
+		return ErrNilReader
+	}
+
 	// Move unconsumed data to the front of buffer
 	copy(c.buf[:n], c.buf[c.cursor:])
 	c.cursor = 0
-
-	if c.eof {
-		c.buf = c.buf[:n]
-
-		return nil
-	}
+	c.buf = c.buf[:cap(c.buf)]
 
 	// Fill the rest of the buffer
 	m, err := io.ReadFull(c.reader, c.buf[n:])
+	if c.seeker != nil {
+		m, err = c.retryRead(n, m, err)
+	}
+
 	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
 		c.buf = c.buf[:n+m]
 		c.eof = true
 	} else if err != nil {
-		return err
+		// Keep the bytes read before the error, a retry continues after them
+		c.buf = c.buf[:n+m]
+
+		return &ReadError{Offset: c.offset + uint64(n+m), Err: err} //nolint:gosec // G115
 	}
 
 	return nil
 }
 
 // Next returns the next chunk from the stream.
-// Returns io.EOF when the stream is exhausted.
+// Returns io.EOF when the stream is exhausted, and a *ReadError when the reader
+// fails; after a transient failure, Next can be called again to resume.
+// An empty stream returns io.EOF on the first call, and a stream shorter than
+// the minimum size, down to a single byte, yields exactly one chunk.
 //
 // The returned Chunk.Data slice is valid until the next call to Next().
 // If you need to keep the data, copy it to your own buffer.
+//
+// When WithChunkDigest is set, Chunk.Digest holds a newly allocated digest
+// of the chunk data. Use NextDigest to avoid the allocation.
 func (c *Chunker) Next() (Chunk, error) {
+	return c.next(nil, nil)
+}
+
+// NextDigest is like Next but writes the chunk digest into dst, which is grown
+// only if it is too small, so reusing dst across calls avoids allocations.
+// Chunk.Digest aliases dst. Returns ErrNoChunkDigest unless WithChunkDigest is set.
+func (c *Chunker) NextDigest(dst []byte) (Chunk, error) {
+	if c.digest == nil {
+		return Chunk{}, ErrNoChunkDigest
+	}
+
+	return c.next(dst[:0], nil)
+}
+
+// next returns the next chunk, appending its digest and ID (if enabled) to
+// digestDst and idDst.
+func (c *Chunker) next(digestDst, idDst []byte) (Chunk, error) {
+	// Invalidate guarded data handed out by the previous call
+	c.generation++
+
+	if c.teeErr != nil {
+		return Chunk{}, c.teeErr
+	}
+
+	var (
+		chunk Chunk
+		err   error
+	)
+
+	if c.br != nil {
+		chunk, err = c.nextBuffered()
+	} else {
+		chunk, err = c.nextInternal()
+	}
+
+	if err != nil {
+		return Chunk{}, err
+	}
+
+	// Parts of a chunk longer than the buffer count as one chunk
+	prior := c.partLen
+	length := prior + chunk.Length
+
+	c.partLen = 0
+	if chunk.Partial {
+		c.partLen = length
+	}
+
+	if c.cfg.tail == TailError && chunk.Last && length < c.core.MinSize() {
+		return Chunk{}, fmt.Errorf("%w: %d bytes, minSize (%d)", ErrShortTail, length, c.core.MinSize())
+	}
+
+	if c.cfg.tee != nil {
+		if err := c.writeTee(chunk); err != nil {
+			return Chunk{}, err
+		}
+	}
+
+	if !chunk.Partial && c.core.forced {
+		c.forcedCuts++
+	}
+
+	if !chunk.Partial && c.cfg.stats {
+		c.stats.add(length)
+	}
+
+	if !chunk.Partial && c.cfg.adaptive {
+		c.adapt(length)
+	}
+
+	if c.cfg.progress != nil {
+		c.reportProgress(chunk)
+	}
+
+	if c.sources != nil {
+		chunk.SourceIndex = c.sources.index(chunk.Offset)
+	}
+
+	chunk.Offset += c.base
+
+	if c.cfg.noHash {
+		chunk.Hash = 0
+	}
+
+	if c.cfg.wide {
+		chunk.Hash128 = [2]uint64{chunk.Hash, wideLane(chunk.Data)}
+	}
+
+	if c.cfg.copyData {
+		chunk.Data = bytes.Clone(chunk.Data)
+	}
+
+	if c.digest != nil {
+		if prior == 0 {
+			c.digest.Reset()
+		}
+
+		c.digest.Write(chunk.Data)
+
+		if !chunk.Partial {
+			chunk.Digest = c.digest.Sum(digestDst)
+		}
+	}
+
+	if c.idHash != nil {
+		if prior == 0 {
+			c.idHash.Reset()
+			c.idHash.Write(c.cfg.chunkID)
+		}
+
+		c.idHash.Write(chunk.Data)
+
+		if !chunk.Partial {
+			chunk.ID = c.idHash.Sum(idDst)
+		}
+	}
+
+	if c.cfg.guardedData {
+		chunk.Guarded = ChunkData{data: chunk.Data, generation: c.generation, current: &c.generation}
+		chunk.Data = nil
+	}
+
+	return chunk, nil
+}
+
+// writeTee writes the data of chunk to the WithTee writer, recording a failure.
+func (c *Chunker) writeTee(chunk Chunk) error {
+	n, err := c.cfg.tee.Write(chunk.Data)
+	if err == nil && n < len(chunk.Data) {
+		err = io.ErrShortWrite
+	}
+
+	if err != nil {
+		c.teeErr = fmt.Errorf("tee write of chunk at offset %d: %w", chunk.Offset+c.base, err)
+	}
+
+	return c.teeErr
+}
+
+// progressInterval is the number of bytes chunked between WithProgress calls.
+const progressInterval = 1 << 20
+
+// reportProgress calls the WithProgress callback for chunk if at least
+// progressInterval bytes were chunked since the last call, or chunk is the last.
+func (c *Chunker) reportProgress(chunk Chunk) {
+	end := chunk.Offset + uint64(chunk.Length)
+	if end < c.progressAt && !chunk.Last {
+		return
+	}
+
+	c.progressAt = end + progressInterval
+	c.cfg.progress(end)
+}
+
+// Process chunks the remaining stream, calling fn for each chunk until EOF.
+// If fn returns an error, Process stops and returns that error; read errors
+// are returned as well. Process returns nil at EOF.
+//
+// Chunk.Data points into the internal buffer and is only valid during the call to fn.
+func (c *Chunker) Process(fn func(Chunk) error) error {
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if err := fn(chunk); err != nil {
+			return err
+		}
+	}
+}
+
+// All returns an iterator over the remaining chunks in the stream, for use with
+// range-over-func:
+//
+//	for chunk, err := range chunker.All() {
+//	    if err != nil {
+//	        return err
+//	    }
+//	    // Process chunk.Data
+//	}
+//
+// Iteration stops cleanly at EOF. A read error is yielded once, with a zero Chunk,
+// and ends the iteration. Breaking out of the loop stops reading from the reader.
+// As with Next, the yielded Chunk.Data is only valid until the next iteration.
+func (c *Chunker) All() iter.Seq2[Chunk, error] {
+	return func(yield func(Chunk, error) bool) {
+		for {
+			chunk, err := c.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				yield(Chunk{}, err)
+
+				return
+			}
+
+			if !yield(chunk, nil) {
+				return
+			}
+		}
+	}
+}
+
+// nextInternal returns the next chunk from the internal buffer.
+func (c *Chunker) nextInternal() (Chunk, error) {
 	if err := c.fillBuffer(); err != nil {
 		return Chunk{}, err
 	}
 
-	if len(c.buf) == 0 {
+	if c.cursor == len(c.buf) {
 		return Chunk{}, io.EOF
 	}
 
 	// Find boundary in available data
 	available := c.buf[c.cursor:]
-	boundary, hash, found := c.core.FindBoundary(available)
+	c.applyMinChunkCount(available)
+
+	limit := len(available)
+	if c.cfg.frameMagic != nil {
+		limit = c.frameCut(available)
+	}
+
+	if c.cfg.partial && !c.eof {
+		// Hold back the last byte, so that a part is never followed by an
+		// empty one when the input ends right after it
+		limit--
+	}
+
+	boundary, hash, found := c.core.FindBoundary(available[:limit])
+
+	if !found && c.cfg.partial && !c.eof {
+		// The chunk does not fit in the buffer, return it in parts
+		return c.nextPart(available[:limit]), nil
+	}
 
 	if !found {
-		// No boundary found - this should only happen at EOF with remaining data
-		// Return all remaining data as final chunk
-		boundary = len(available)
+		// No boundary found - this should only happen at EOF with remaining
+		// data, or before a frame magic
+		boundary = limit
+	} else if c.cfg.delimWindow != 0 {
+		boundary = c.moveToDelimiter(available[:limit], boundary)
+	}
+
+	if c.eof && c.cfg.tail == TailMerge {
+		boundary = c.mergeTail(available, boundary)
 	}
 
 	chunk := Chunk{
@@ -128,21 +563,512 @@ func (c *Chunker) Next() (Chunk, error) {
 	c.offset += uint64(boundary) //nolint:gosec // G115
 	c.core.Reset()
 
+	chunk.Last = c.eof && c.cursor == len(c.buf)
+
+	return chunk, nil
+}
+
+// nextPart returns data, all of the chunk that is buffered, as a part of a
+// chunk longer than the buffer (WithPartialChunks). The core keeps hashing the
+// chunk from where it stopped on the next call.
+func (c *Chunker) nextPart(data []byte) Chunk {
+	chunk := Chunk{
+		Offset:  c.offset,
+		Length:  uint32(len(data)), //nolint:gosec // G115
+		Data:    data,
+		Partial: true,
+	}
+
+	c.cursor += len(data)
+	c.offset += uint64(len(data)) //nolint:gosec // G115
+
+	return chunk
+}
+
+// nextBuffered returns the next chunk by peeking into the bufio.Reader's buffer.
+// The returned data stays valid until the next read from the bufio.Reader.
+func (c *Chunker) nextBuffered() (Chunk, error) {
+	// Peeking past maxSize tells whether the chunk is the final one
+	available, err := c.br.Peek(c.cfg.lookahead())
+	if err != nil && !errors.Is(err, io.EOF) {
+		return Chunk{}, &ReadError{Offset: c.offset + uint64(len(available)), Err: err}
+	}
+
+	if len(available) == 0 {
+		c.brEOF = true
+
+		return Chunk{}, io.EOF
+	}
+
+	c.applyMinChunkCount(available)
+
+	limit := len(available)
+	if c.cfg.frameMagic != nil {
+		limit = c.frameCut(available)
+	}
+
+	boundary, hash, found := c.core.FindBoundary(available[:limit])
+	if !found {
+		// Peek returned less than maxSize, so this is the final chunk, or
+		// the chunk ends before a frame magic
+		boundary = limit
+	} else if c.cfg.delimWindow != 0 {
+		boundary = c.moveToDelimiter(available[:limit], boundary)
+	}
+
+	if errors.Is(err, io.EOF) && c.cfg.tail == TailMerge {
+		boundary = c.mergeTail(available, boundary)
+	}
+
+	last := errors.Is(err, io.EOF) && boundary == len(available)
+	c.brEOF = last
+
+	// Discard only advances the read position, the peeked bytes stay in place
+	if _, err := c.br.Discard(boundary); err != nil {
+		return Chunk{}, &ReadError{Offset: c.offset, Err: err}
+	}
+
+	chunk := Chunk{
+		Offset: c.offset,
+		Length: uint32(boundary), //nolint:gosec // G115
+		Hash:   hash,
+		Data:   available[:boundary],
+		Last:   last,
+	}
+
+	c.offset += uint64(boundary) //nolint:gosec // G115
+	c.core.Reset()
+
 	return chunk, nil
 }
 
+// mergeTail returns the end of the chunk ending at boundary in available, the
+// rest of the input, extended over the final bytes if they are shorter than
+// minSize (TailMerge).
+func (c *Chunker) mergeTail(available []byte, boundary int) int {
+	if tail := len(available) - boundary; tail > 0 && tail < int(c.core.MinSize()) {
+		return len(available)
+	}
+
+	return boundary
+}
+
+// minShrinkSize is the smallest minSize WithMinChunkCount lowers minSize to.
+const minShrinkSize = 64
+
+// applyMinChunkCount lowers the core sizes for WithMinChunkCount when data, at
+// the start of the stream, is the whole input and chunks into too few pieces.
+func (c *Chunker) applyMinChunkCount(data []byte) {
+	// Reading fewer than maxSize bytes at the start means the input ended
+	if c.cfg.minChunks <= 1 || c.offset != 0 || len(data) >= int(c.core.MaxSize()) {
+		return
+	}
+
+	cfg := c.cfg
+	cfg.maxRatio = 0 // maxSize is scaled directly
+
+	for c.countChunks(data) < c.cfg.minChunks && cfg.minSize/2 >= minShrinkSize {
+		cfg.minSize /= 2
+		cfg.targetSize /= 2
+		cfg.maxSize /= 2
+		cfg.normSize /= 2
+
+		if cfg.validate() != nil {
+			break
+		}
+
+		c.core.setSizes(&cfg)
+		c.shrunk = true
+	}
+}
+
+// countChunks returns how many chunks the core splits data into, leaving it reset.
+func (c *Chunker) countChunks(data []byte) int {
+	count := 0
+
+	for len(data) > 0 {
+		boundary, _, _ := c.core.FindBoundary(data)
+		data = data[boundary:]
+		count++
+
+		c.core.Reset()
+	}
+
+	return count
+}
+
 // Reset resets the chunker to start processing a new stream.
 // The reader is replaced with the provided one, and all state is cleared.
 func (c *Chunker) Reset(r io.Reader) {
+	if c.shrunk || c.adapted {
+		// Undo WithMinChunkCount and WithAdaptiveTarget for the previous input
+		c.core.setSizes(&c.cfg)
+		c.shrunk, c.adapted = false, false
+	}
+
+	c.adaptSum, c.adaptCount = 0, 0
+
 	c.reader = r
+	c.br = nil
+	c.sources = nil
+	c.generation++
+
+	if c.external {
+		// Never read into memory owned by the caller
+		c.buf = nil
+		c.external = false
+	}
+
+	if br, ok := r.(*bufio.Reader); ok && br.Size() >= c.cfg.lookahead() {
+		// Already buffered, peek into it instead of double buffering
+		c.br = br
+	} else if c.buf == nil {
+		c.buf = c.cfg.buffer
+		if c.buf == nil {
+			c.buf = make([]byte, c.cfg.bufferSize)
+		}
+	}
+
+	c.seeker = nil
+	if s, ok := r.(io.Seeker); ok && c.cfg.retries > 0 && c.br == nil {
+		if pos, err := s.Seek(0, io.SeekCurrent); err == nil {
+			c.seeker, c.seekStart = s, pos
+		}
+	}
+
 	c.core.Reset()
+	c.stats = sizeStats{}
+	c.forcedCuts = 0
+	c.progressAt = 0
+	c.teeErr = nil
+	c.partLen = 0
+	c.brEOF = false
+	c.frameAt, c.frameSearched = 0, 0
 	c.buf = c.buf[:cap(c.buf)] // Restore buffer to full capacity
 	c.cursor = len(c.buf)      // Start with empty buffer
 	c.offset = 0
+	c.base = 0
 	c.eof = false
 }
 
+// Fork returns a child chunker with the same options, for chunking a nested
+// stream such as an archive member while c is in the middle of the outer one,
+// without disturbing it. Call Reset on the child with the sub-reader before use.
+//
+// The child is created on the first call and returned again by later ones, so
+// its buffer is allocated once however many members are chunked: finish with
+// one fork before forking again. The child can fork in turn for deeper nesting.
+// It starts from the configured sizes and masks even if WithMinChunkCount or
+// WithAdaptiveTarget changed those of c. It never reads into a WithBuffer
+// buffer, and does not write to the WithTee writer or call the WithProgress
+// callback, which belong to the outer stream.
+func (c *Chunker) Fork() *Chunker {
+	if c.fork == nil {
+		cfg := c.cfg
+		cfg.buffer = nil
+		cfg.tee, cfg.progress = nil, nil
+
+		child := &Chunker{core: c.core, cfg: cfg}
+		child.core.setSizes(&cfg)
+		child.core.Reset()
+
+		if cfg.digest != nil {
+			child.digest = cfg.digest()
+		}
+
+		if cfg.chunkID != nil {
+			child.idHash = sha256.New()
+		}
+
+		c.fork = child
+	}
+
+	return c.fork
+}
+
+// Reconfigure applies opts on top of the chunker's current options and uses the
+// result for all following chunks, without reallocating the Chunker. Validation
+// errors are returned as from NewChunker and leave the chunker unchanged.
+//
+// The internal buffer is reused if it is still large enough and grown otherwise,
+// keeping any bytes not yet returned. A buffer passed with WithBuffer replaces
+// it, and must also be able to hold those bytes. Reconfigure is typically
+// called between streams, before Reset, but it may also be called between calls
+// to Next.
+func (c *Chunker) Reconfigure(opts ...Option) error {
+	cfg := c.cfg
+	cfg.buffer = nil // Only set by a WithBuffer in opts
+
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return err
+	}
+
+	buffer := cfg.buffer
+	if buffer == nil && len(c.cfg.buffer) >= cfg.minBufferSize() {
+		// Keep the supplied buffer while it is large enough
+		cfg.buffer = c.cfg.buffer
+		cfg.bufferSize = len(cfg.buffer)
+	} else if buffer != nil && c.br == nil && !c.external && len(c.buf)-c.cursor > len(buffer) {
+		return fmt.Errorf("%w: buffer (%d), unconsumed (%d)", ErrBufferTooSmall, len(buffer), len(c.buf)-c.cursor)
+	}
+
+	if err := cfg.validateBuffer(); err != nil {
+		return err
+	}
+
+	c.cfg = cfg
+	c.core = newChunkerCoreWithConfig(&cfg)
+	c.shrunk, c.adapted = false, false
+	c.adaptSum, c.adaptCount = 0, 0
+	c.fork = nil  // Forks use the new options from now on
+	c.partLen = 0 // The new core starts a new chunk
+
+	c.digest = nil
+	if cfg.digest != nil {
+		c.digest = cfg.digest()
+	}
+
+	c.idHash = nil
+	if cfg.chunkID != nil {
+		c.idHash = sha256.New()
+	}
+
+	if c.br != nil && c.br.Size() < cfg.lookahead() {
+		// The bufio.Reader can no longer hold a whole chunk, read from it instead
+		c.br = nil
+		c.buf = c.buf[:cap(c.buf)]
+		c.cursor = len(c.buf)
+	}
+
+	switch {
+	case c.br != nil || c.external:
+		// No internal buffer in use; Reset adopts a supplied one
+	case buffer != nil:
+		c.replaceBuffer(buffer)
+	case cap(c.buf) < cfg.bufferSize:
+		c.replaceBuffer(make([]byte, cfg.bufferSize))
+	}
+
+	return nil
+}
+
+// replaceBuffer replaces the internal buffer with buf, which must be large
+// enough, keeping the unconsumed bytes in the layout fillBuffer expects.
+func (c *Chunker) replaceBuffer(buf []byte) {
+	size := len(buf)
+	unconsumed := c.buf[c.cursor:]
+
+	if c.eof {
+		// Nothing more will be read; the data ends where the buffer does
+		c.buf = buf[:copy(buf, unconsumed)]
+		c.cursor = 0
+
+		return
+	}
+
+	c.cursor = size - copy(buf[size-len(unconsumed):], unconsumed)
+	c.buf = buf
+}
+
+// RechunkTail chunks the currently buffered, unconsumed bytes with the chunker's
+// options overridden by opts, and returns the resulting chunks.
+//
+// This is meant for tuning against a live sample: it does not read from the
+// underlying reader, does not advance the chunker, and the tail of the buffer
+// is treated as the end of the data. The returned Chunk.Data slices point into
+// the internal buffer and are valid until the next call to Next().
+func (c *Chunker) RechunkTail(opts ...Option) ([]Chunk, error) {
+	cfg := c.cfg
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	core := newChunkerCoreWithConfig(&cfg)
+
+	var tail []byte
+	if c.br != nil {
+		// Buffered bytes can always be peeked without reading
+		tail, _ = c.br.Peek(c.br.Buffered())
+	} else {
+		tail = c.buf[c.cursor:]
+	}
+
+	var chunks []Chunk
+
+	offset := c.base + c.offset
+
+	for len(tail) > 0 {
+		boundary, hash, found := core.FindBoundary(tail)
+		if !found {
+			boundary = len(tail)
+		}
+
+		chunks = append(chunks, Chunk{
+			Offset: offset,
+			Length: uint32(boundary), //nolint:gosec // G115
+			Hash:   hash,
+			Data:   tail[:boundary],
+		})
+
+		tail = tail[boundary:]
+		offset += uint64(boundary) //nolint:gosec // G115
+
+		core.Reset()
+	}
+
+	return chunks, nil
+}
+
+// Skip discards the next n bytes of the stream without returning them as
+// chunks, and starts a fresh chunk after them: the next call to Next returns a
+// chunk at Offset()+n, as when chunking a stream that starts there. This lets
+// a resumed upload skip the bytes already handled.
+//
+// Skip returns the number of bytes discarded, which is less than n only if
+// the stream ended first, in which case the error is io.EOF. Data returned by
+// earlier calls to Next may be overwritten.
+func (c *Chunker) Skip(n int64) (int64, error) {
+	if n < 0 {
+		return 0, fmt.Errorf("%w: %d", ErrNegativeSkip, n)
+	}
+
+	c.generation++
+
+	var skipped int64
+
+	for skipped < n {
+		var (
+			discarded int
+			err       error
+		)
+
+		if c.br != nil {
+			discarded, err = c.br.Discard(int(min(n-skipped, math.MaxInt32)))
+			c.brEOF = errors.Is(err, io.EOF)
+
+			if err != nil && !errors.Is(err, io.EOF) {
+				err = &ReadError{Offset: c.offset + uint64(discarded), Err: err} //nolint:gosec // G115
+			}
+		} else if err = c.fillBuffer(); err == nil {
+			discarded = int(min(n-skipped, int64(len(c.buf)-c.cursor)))
+			c.cursor += discarded
+
+			if discarded == 0 {
+				err = io.EOF
+			}
+		}
+
+		skipped += int64(discarded)
+		c.offset += uint64(discarded) //nolint:gosec // G115
+
+		if err != nil {
+			c.core.Reset()
+			c.partLen = 0
+
+			return skipped, err
+		}
+	}
+
+	c.core.Reset()
+	c.partLen = 0
+
+	return skipped, nil
+}
+
 // Offset returns the current absolute offset in the stream.
 func (c *Chunker) Offset() uint64 {
-	return c.offset
+	return c.base + c.offset
+}
+
+// AtEOF reports whether the input is exhausted: the reader reached its end and
+// every byte read has been returned in a chunk, so the next call to Next
+// returns io.EOF. After Next returns a chunk it equals Chunk.Last, which lets
+// the final chunk be followed by finalizing work, such as flushing a writer,
+// without another call to Next. The end is only detected once read: before
+// the first call to Next, or after Skip, AtEOF may report false for an input
+// that has nothing left.
+func (c *Chunker) AtEOF() bool {
+	if c.br != nil {
+		return c.brEOF
+	}
+
+	return c.eof && c.cursor == len(c.buf)
+}
+
+// Buffered returns the number of bytes the chunker has read ahead from its
+// reader but not yet returned in a chunk. The chunker owns those bytes: they
+// are no longer available from the reader, so code that seeks the reader or
+// hands it off must account for them; the reader's position is Offset() +
+// Buffered(). When reading from a *bufio.Reader large enough to peek into, the
+// read-ahead stays in the bufio.Reader and Buffered returns 0.
+func (c *Chunker) Buffered() int {
+	if c.br != nil {
+		return 0
+	}
+
+	return len(c.buf) - c.cursor
+}
+
+// ForcedCuts returns how many chunks returned since the chunker was created or
+// last reset were cut at the hard maxSize limit rather than at a content-defined
+// boundary. A high share of forced cuts, on highly repetitive data or with masks
+// that rarely match, means the sizes do not suit the data: such chunks do not
+// survive insertions and deduplicate poorly.
+func (c *Chunker) ForcedCuts() uint64 {
+	return c.forcedCuts
+}
+
+// Stats returns the size distribution of the chunks returned since the chunker
+// was created or last reset. It is only collected with WithStats and is the
+// zero value otherwise.
+func (c *Chunker) Stats() ChunkStats {
+	return c.stats.summary()
+}
+
+// MinSize returns the minimum chunk size.
+func (c *Chunker) MinSize() uint32 {
+	return c.core.MinSize()
+}
+
+// TargetSize returns the target chunk size.
+func (c *Chunker) TargetSize() uint32 {
+	return c.core.TargetSize()
+}
+
+// MaxSize returns the largest chunk the chunker returns: maxSize, or
+// maxSize+minSize-1 with TailMerge, whose merged final chunk may exceed maxSize.
+// No chunk is larger, so it is a safe size for downstream chunk buffers.
+func (c *Chunker) MaxSize() uint32 {
+	if c.cfg.tail == TailMerge {
+		return uint32(min(uint64(c.core.maxSize)+uint64(c.core.minSize)-1, math.MaxUint32)) //nolint:gosec // G115
+	}
+
+	return c.core.MaxSize()
+}
+
+// NormSize returns the normalization boundary.
+func (c *Chunker) NormSize() uint32 {
+	return c.core.NormSize()
+}
+
+// NormLevel returns the normalization level.
+func (c *Chunker) NormLevel() uint8 {
+	return c.core.NormLevel()
+}
+
+// Seed returns the seed the Gear hash table was generated from.
+func (c *Chunker) Seed() uint64 {
+	return c.core.Seed()
 }
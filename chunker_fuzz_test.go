@@ -108,3 +108,97 @@ func FuzzChunkerCore(f *testing.F) {
 		}
 	})
 }
+
+func FuzzChunkerCoreSegmented(f *testing.F) {
+	f.Add(make([]byte, 4096), uint32(64), uint32(256), uint32(1024), uint8(2), uint16(1), uint16(7))
+	f.Add(
+		[]byte("segmented data must chunk exactly like contiguous data, whatever the split"),
+		uint32(8),
+		uint32(16),
+		uint32(32),
+		uint8(1),
+		uint16(3),
+		uint16(5),
+	)
+	f.Fuzz(func(t *testing.T, data []byte, minimum, target, maximum uint32, norm uint8, segA, segB uint16) {
+		opts := []fastcdc.Option{
+			fastcdc.WithMinSize(minimum),
+			fastcdc.WithTargetSize(target),
+			fastcdc.WithMaxSize(maximum),
+			fastcdc.WithNormalization(norm),
+		}
+
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			return
+		}
+
+		// Reference boundaries from contiguous data
+		var want []int
+
+		for offset := 0; offset < len(data); {
+			boundary, _, found := core.FindBoundary(data[offset:])
+			if !found {
+				break
+			}
+
+			offset += boundary
+			want = append(want, offset)
+
+			core.Reset()
+		}
+
+		core.Reset()
+
+		// Feed the same data in segments alternating between two sizes
+		var got []int
+
+		sizes := [2]int{int(segA)%4096 + 1, int(segB)%4096 + 1}
+		chunkStart := 0
+
+		for offset, i := 0, 0; offset < len(data); i++ {
+			end := min(offset+sizes[i%2], len(data))
+
+			segment := data[offset:end]
+			for len(segment) > 0 {
+				boundary, _, found := core.FindBoundary(segment)
+				if boundary > len(segment) {
+					t.Fatalf("boundary %d exceeds segment length %d", boundary, len(segment))
+				}
+
+				if !found {
+					if boundary != len(segment) {
+						t.Fatalf("not found but boundary %d != segment length %d", boundary, len(segment))
+					}
+
+					break
+				}
+
+				cut := end - len(segment) + boundary
+
+				length := uint32(cut - chunkStart) //nolint:gosec // G115
+				if length < minimum || length > maximum {
+					t.Fatalf("chunk length %d outside [%d, %d]", length, minimum, maximum)
+				}
+
+				got = append(got, cut)
+				chunkStart = cut
+				segment = segment[boundary:]
+
+				core.Reset()
+			}
+
+			offset = end
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("boundary count mismatch: got %d, want %d", len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i] != want[i] {
+				t.Fatalf("boundary %d mismatch: got %d, want %d", i, got[i], want[i])
+			}
+		}
+	})
+}
@@ -1,13 +1,19 @@
 package fastcdc_test
 
 import (
+	"bufio"
 	"bytes"
 	"crypto/rand"
+	"crypto/sha256"
 	"errors"
+	"fmt"
 	"io"
 	"math"
+	"slices"
+	"strings"
 	"sync"
 	"testing"
+	"testing/iotest"
 
 	"github.com/kalbasit/fastcdc"
 )
@@ -520,6 +526,150 @@ func TestChunkerPool(t *testing.T) {
 	pool.Put(chunker)
 }
 
+func TestShardedChunkerPool(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fastcdc.NewShardedChunkerPool(0); !errors.Is(err, fastcdc.ErrInvalidShardCount) {
+		t.Errorf("0 shards: got %v, want ErrInvalidShardCount", err)
+	}
+
+	pool, err := fastcdc.NewShardedChunkerPool(3, fastcdc.WithMinSize(4*1024), fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithChunkDigest(sha256.New))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Warm(5)
+
+	data := randBytes(256*1024, 1)
+	want := chunkDigests(t, data)
+
+	var wg sync.WaitGroup
+
+	for range 4 {
+		wg.Go(func() {
+			for range 10 {
+				chunker, err := pool.Get(bytes.NewReader(data))
+				if err != nil {
+					t.Error(err)
+
+					return
+				}
+
+				var digests [][]byte
+
+				err = chunker.Process(func(chunk fastcdc.Chunk) error {
+					digests = append(digests, chunk.Digest)
+
+					return nil
+				})
+				if err != nil {
+					t.Error(err)
+				}
+
+				if !slices.EqualFunc(digests, want, func(d []byte, chunk fastcdc.Chunk) bool {
+					return bytes.Equal(d, chunk.Digest)
+				}) {
+					t.Error("pooled chunker produced different chunks")
+				}
+
+				pool.Put(chunker)
+			}
+		})
+	}
+
+	wg.Wait()
+
+	// Chunkers from elsewhere are accepted too
+	pool.Put(mustChunker(t, bytes.NewReader(nil)))
+}
+
+// TestChunkerPoolBufferBound tests that Put does not keep chunkers whose buffer
+// grew past the pool's buffer size.
+func TestChunkerPoolBufferBound(t *testing.T) {
+	t.Parallel()
+
+	pool, err := fastcdc.NewChunkerPool(fastcdc.WithBufferSize(512 * 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randBytes(256*1024, 1330)
+
+	chunker, err := pool.Get(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := chunker.Reconfigure(fastcdc.WithBufferSize(4 * 1024 * 1024)); err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Put(chunker)
+
+	for range 4 {
+		chunker, err := pool.Get(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Contains(chunker.String(), "size=4194304 ") {
+			t.Fatalf("pool kept the grown buffer: %s", chunker)
+		}
+
+		pool.Put(chunker)
+	}
+}
+
+// TestChunkerPoolWarm tests that warmed chunkers produce the same chunks as new ones.
+func TestChunkerPoolWarm(t *testing.T) {
+	t.Parallel()
+
+	// Same options as chunkDigests
+	pool, err := fastcdc.NewChunkerPool(
+		fastcdc.WithMinSize(4*1024),
+		fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithChunkDigest(sha256.New),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pool.Warm(4)
+	pool.Warm(0)
+
+	data := randBytes(256*1024, 1)
+	want := chunkDigests(t, data)
+
+	for range 4 {
+		chunker, err := pool.Get(bytes.NewReader(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var i int
+
+		for ; ; i++ {
+			chunk, err := chunker.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if i >= len(want) || chunk.Offset != want[i].Offset || !bytes.Equal(chunk.Digest, want[i].Digest) {
+				t.Fatalf("chunk %d differs from a new chunker", i)
+			}
+		}
+
+		if i != len(want) {
+			t.Fatalf("got %d chunks, want %d", i, len(want))
+		}
+	}
+}
+
 // TestChunkerSmallData tests chunking of data smaller than minSize.
 func TestChunkerSmallData(t *testing.T) {
 	t.Parallel()
@@ -600,3 +750,3505 @@ func TestOptionsValidation(t *testing.T) {
 		})
 	}
 }
+
+// TestOptionsValidationJoined tests that all violated constraints are reported together.
+func TestOptionsValidationJoined(t *testing.T) {
+	t.Parallel()
+
+	_, err := fastcdc.NewConfig(
+		fastcdc.WithMinSize(100*1024), // Above the default targetSize
+		fastcdc.WithTable(fastcdc.GenerateTable(1)),
+		fastcdc.WithSeed(2),
+		fastcdc.WithoutHash(),
+		fastcdc.WithWideFingerprint(),
+	)
+
+	for _, want := range []error{fastcdc.ErrTargetSizeTooSmall, fastcdc.ErrTableWithSeed, fastcdc.ErrWideWithoutHash} {
+		if !errors.Is(err, want) {
+			t.Errorf("got %v, want it to include %v", err, want)
+		}
+	}
+
+	if errors.Is(err, fastcdc.ErrMaxSizeTooSmall) {
+		t.Errorf("got %v, reporting a constraint that holds", err)
+	}
+
+	// A single violation is returned as is
+	if _, err := fastcdc.NewConfig(fastcdc.WithoutHash(), fastcdc.WithWideFingerprint()); err != fastcdc.ErrWideWithoutHash { //nolint:errorlint // not joined
+		t.Errorf("got %v, want ErrWideWithoutHash itself", err)
+	}
+}
+
+func TestConfigWarnings(t *testing.T) {
+	t.Parallel()
+
+	for _, opts := range [][]fastcdc.Option{
+		nil,
+		{fastcdc.WithNormalization(0)},
+		{fastcdc.WithFixedSize(4096)},
+		{fastcdc.WithMinSize(16 * 1024), fastcdc.WithTargetSize(16*1024 + 200), fastcdc.WithNormSize(16*1024 + 100)},
+	} {
+		cfg, err := fastcdc.NewConfig(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if warnings := cfg.Warnings(); warnings != nil {
+			t.Errorf("unexpected warnings %q", warnings)
+		}
+	}
+
+	// 200 >> 8 leaves no room between minSize and normSize
+	cfg, err := fastcdc.NewConfig(fastcdc.WithMinSize(16*1024), fastcdc.WithTargetSize(16*1024+200),
+		fastcdc.WithNormalization(8))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if warnings := cfg.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "normalization region is empty") {
+		t.Errorf("got warnings %q, want the empty normalization region", warnings)
+	}
+
+	// maxSize is set explicitly below MinMaxSizeRatio times targetSize
+	cfg, err = fastcdc.NewConfig(fastcdc.WithTargetSize(64*1024), fastcdc.WithMaxSize(64*1024+1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if warnings := cfg.Warnings(); len(warnings) != 1 || !strings.Contains(warnings[0], "less than 2 times targetSize") {
+		t.Errorf("got warnings %q, want the small maxSize", warnings)
+	}
+}
+
+// TestChunkMatchesStored tests the collision-resolution byte compare.
+func TestChunkMatchesStored(t *testing.T) {
+	t.Parallel()
+
+	chunk := fastcdc.Chunk{
+		Offset: 0,
+		Length: 5,
+		Hash:   0xdeadbeef,
+		Data:   []byte("hello"),
+	}
+
+	if !chunk.MatchesStored([]byte("hello")) {
+		t.Error("Expected identical data to match")
+	}
+
+	// Same hash, different length
+	if chunk.MatchesStored([]byte("hello!")) {
+		t.Error("Expected different-length data not to match")
+	}
+
+	// Same length, different content
+	if chunk.MatchesStored([]byte("jello")) {
+		t.Error("Expected same-length different data not to match")
+	}
+
+	if chunk.MatchesStored(nil) {
+		t.Error("Expected nil stored data not to match")
+	}
+}
+
+// TestChunkerBufioReader verifies that chunking through a *bufio.Reader
+// produces the same boundaries as chunking the raw reader.
+func TestChunkerBufioReader(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	collect := func(r io.Reader) []fastcdc.Chunk {
+		chunker, err := fastcdc.NewChunker(r, fastcdc.WithTargetSize(64*1024))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var chunks []fastcdc.Chunk
+
+		for {
+			chunk, err := chunker.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !bytes.Equal(chunk.Data, data[chunk.Offset:chunk.Offset+uint64(chunk.Length)]) {
+				t.Fatalf("Chunk data mismatch at offset %d", chunk.Offset)
+			}
+
+			chunk.Data = nil
+			chunks = append(chunks, chunk)
+		}
+
+		return chunks
+	}
+
+	want := collect(bytes.NewReader(data))
+
+	readers := map[string]io.Reader{
+		"large bufio": bufio.NewReaderSize(bytes.NewReader(data), 512*1024),
+		"small bufio": bufio.NewReaderSize(bytes.NewReader(data), 4096),
+	}
+
+	for name, r := range readers {
+		got := collect(r)
+		if len(got) != len(want) {
+			t.Fatalf("%s: chunk count mismatch: %d vs %d", name, len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i].Offset != want[i].Offset || got[i].Length != want[i].Length || got[i].Hash != want[i].Hash {
+				t.Errorf("%s: chunk %d mismatch: %+v vs %+v", name, i, got[i], want[i])
+			}
+		}
+	}
+}
+
+// TestChunkerRechunkTail verifies rechunking the buffered tail with new options.
+func TestChunkerRechunkTail(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(
+		bytes.NewReader(data),
+		fastcdc.WithMinSize(2*1024),
+		fastcdc.WithTargetSize(8*1024),
+		fastcdc.WithMaxSize(32*1024),
+		fastcdc.WithBufferSize(len(data)),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := chunker.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rechunk := func(level uint8) []fastcdc.Chunk {
+		chunks, err := chunker.RechunkTail(fastcdc.WithNormalization(level))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var total uint64
+		for _, chunk := range chunks {
+			total += uint64(chunk.Length)
+		}
+
+		if chunks[0].Offset != uint64(first.Length) || total != uint64(len(data))-uint64(first.Length) {
+			t.Fatalf("Rechunked tail does not cover the buffered bytes: offset=%d total=%d", chunks[0].Offset, total)
+		}
+
+		return chunks
+	}
+
+	chunks0 := rechunk(0)
+	chunks3 := rechunk(3)
+
+	same := len(chunks0) == len(chunks3)
+	for i := 0; same && i < len(chunks0); i++ {
+		same = chunks0[i].Length == chunks3[i].Length
+	}
+
+	if same {
+		t.Error("Different normalization levels produced identical chunking")
+	}
+
+	// The real cursor must not have moved
+	second, err := chunker.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Offset != uint64(first.Length) {
+		t.Errorf("Next() after RechunkTail at offset %d, want %d", second.Offset, first.Length)
+	}
+
+	if _, err := chunker.RechunkTail(fastcdc.WithMinSize(64 * 1024)); err == nil {
+		t.Error("Expected error for invalid options")
+	}
+}
+
+// TestChunkerGuardedData verifies that guarded data is only accessible
+// until the chunker advances.
+func TestChunkerGuardedData(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 512*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithGuardedData(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first, err := chunker.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Data != nil {
+		t.Error("Expected Chunk.Data to be nil with guarded data")
+	}
+
+	// In-window access succeeds
+	if !bytes.Equal(first.Guarded.Bytes(), data[:first.Length]) {
+		t.Error("Guarded data does not match input")
+	}
+
+	if _, err := chunker.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if first.Guarded.Valid() {
+		t.Error("Expected guarded data to be invalid after Next()")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic when accessing guarded data after Next()")
+		}
+	}()
+
+	_ = first.Guarded.Bytes()
+}
+
+// TestNormalizationStrength verifies that a stronger small mask cuts more
+// chunks inside the normalized region.
+func TestNormalizationStrength(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 4*1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	normalizedFraction := func(delta uint8) float64 {
+		core, err := fastcdc.NewChunkerCore(
+			fastcdc.WithMinSize(2*1024),
+			fastcdc.WithTargetSize(16*1024),
+			fastcdc.WithMaxSize(64*1024),
+			fastcdc.WithNormalization(1),
+			fastcdc.WithNormalizationStrength(delta),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var chunks, normalized int
+
+		for offset := 0; offset < len(data); {
+			boundary, _, found := core.FindBoundary(data[offset:])
+			if !found {
+				break
+			}
+
+			chunks++
+
+			if boundary <= int(core.NormSize()) {
+				normalized++
+			}
+
+			offset += boundary
+
+			core.Reset()
+		}
+
+		return float64(normalized) / float64(chunks)
+	}
+
+	f1 := normalizedFraction(1)
+	f3 := normalizedFraction(3)
+
+	t.Logf("Chunks cut in normalized region: delta=1 %.2f, delta=3 %.2f", f1, f3)
+
+	if f3 <= f1 {
+		t.Errorf("Expected delta=3 to cut more in the normalized region: %.2f <= %.2f", f3, f1)
+	}
+
+	_, err := fastcdc.NewChunkerCore(fastcdc.WithTargetSize(64*1024), fastcdc.WithNormalizationStrength(16))
+	if !errors.Is(err, fastcdc.ErrInvalidNormStrength) {
+		t.Errorf("Expected ErrInvalidNormStrength, got %v", err)
+	}
+}
+
+// TestMaxChunkSizeRatio verifies maxSize derivation and ratio validation.
+func TestMaxChunkSizeRatio(t *testing.T) {
+	t.Parallel()
+
+	// Benchmark configurations use maxSize = 4 * targetSize
+	for _, target := range []uint32{32 * 1024, 64 * 1024, 256 * 1024, 1024 * 1024} {
+		core, err := fastcdc.NewChunkerCore(
+			fastcdc.WithMinSize(target/4),
+			fastcdc.WithMaxChunkSizeRatio(fastcdc.RecommendedMaxSizeRatio),
+			fastcdc.WithTargetSize(target),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if core.MaxSize() != 4*target {
+			t.Errorf("targetSize %d: maxSize = %d, want %d", target, core.MaxSize(), 4*target)
+		}
+	}
+
+	// Last of WithMaxSize and WithMaxChunkSizeRatio wins
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithMaxChunkSizeRatio(3), fastcdc.WithMaxSize(200*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if core.MaxSize() != 200*1024 {
+		t.Errorf("maxSize = %d, want %d", core.MaxSize(), 200*1024)
+	}
+
+	for _, ratio := range []float64{1.5, 0, -4, math.NaN()} {
+		_, err := fastcdc.NewChunkerCore(fastcdc.WithMaxChunkSizeRatio(ratio))
+		if !errors.Is(err, fastcdc.ErrInvalidMaxSizeRatio) {
+			t.Errorf("ratio %g: expected ErrInvalidMaxSizeRatio, got %v", ratio, err)
+		}
+	}
+
+	_, err = fastcdc.NewChunkerCore(fastcdc.WithTargetSize(1<<30), fastcdc.WithMaxChunkSizeRatio(8))
+	if !errors.Is(err, fastcdc.ErrInvalidMaxSizeRatio) {
+		t.Errorf("Expected ErrInvalidMaxSizeRatio on overflow, got %v", err)
+	}
+}
+
+// TestAlgorithmV1Golden pins the boundaries produced by AlgorithmV1.
+// These values must never change; a change in boundaries requires a new
+// algorithm version instead.
+func TestAlgorithmV1Golden(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1230)
+
+	golden := map[uint64][]int{
+		0: {262144, 285549, 379397, 404209, 428775, 545932, 654339, 728747, 794886, 901579, 926065, 954629, 979505},
+		12345: {
+			20955, 43811, 85813, 130184, 147137, 214081, 430197, 448750, 570657,
+			588423, 617671, 635621, 716213, 748807, 849997, 870233, 988172,
+		},
+	}
+
+	for seed, want := range golden {
+		core, err := fastcdc.NewChunkerCore(fastcdc.WithAlgorithmVersion(fastcdc.AlgorithmV1), fastcdc.WithSeed(seed))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []int
+
+		for offset := 0; offset < len(data); {
+			boundary, _, found := core.FindBoundary(data[offset:])
+			if !found {
+				break
+			}
+
+			offset += boundary
+			got = append(got, offset)
+
+			core.Reset()
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("seed %d: boundaries changed:\ngot  %v\nwant %v", seed, got, want)
+		}
+	}
+
+	_, err := fastcdc.NewChunkerCore(fastcdc.WithAlgorithmVersion(0))
+	if !errors.Is(err, fastcdc.ErrUnsupportedAlgorithmVersion) {
+		t.Errorf("Expected ErrUnsupportedAlgorithmVersion, got %v", err)
+	}
+}
+
+// TestChunkerAll tests the range-over-func iterator.
+func TestChunkerAll(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithTargetSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		rebuilt []byte
+		count   int
+	)
+
+	for chunk, err := range chunker.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rebuilt = append(rebuilt, chunk.Data...)
+		count++
+	}
+
+	if !bytes.Equal(rebuilt, data) {
+		t.Error("Iterated chunks do not reconstruct the input")
+	}
+
+	// Early break leaves the chunker positioned after the last yielded chunk
+	chunker.Reset(bytes.NewReader(data))
+
+	var first fastcdc.Chunk
+
+	for chunk, err := range chunker.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first = chunk
+
+		break
+	}
+
+	next, err := chunker.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if next.Offset != uint64(first.Length) {
+		t.Errorf("Next() after break at offset %d, want %d", next.Offset, first.Length)
+	}
+
+	t.Logf("Iterated %d chunks", count)
+}
+
+// TestChunkerDigest verifies per-chunk content digests.
+func TestChunkerDigest(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 10*1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithChunkDigest(sha256.New))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunk, err := chunker.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := sha256.Sum256(chunk.Data)
+	if !bytes.Equal(chunk.Digest, want[:]) {
+		t.Errorf("Digest mismatch: got %x, want %x", chunk.Digest, want)
+	}
+
+	plain, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chunk, _ := plain.Next(); chunk.Digest != nil {
+		t.Error("Expected nil digest without WithChunkDigest")
+	}
+
+	if _, err := plain.NextDigest(nil); !errors.Is(err, fastcdc.ErrNoChunkDigest) {
+		t.Errorf("Expected ErrNoChunkDigest, got %v", err)
+	}
+}
+
+// TestChunkerNextDigestAllocs verifies that NextDigest reuses the caller's buffer.
+//
+//nolint:paralleltest // AllocsPerRun cannot be used in parallel tests
+func TestChunkerNextDigestAllocs(t *testing.T) {
+	data := make([]byte, 10*1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithChunkDigest(sha256.New))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunk fastcdc.Chunk
+
+	dst := make([]byte, 0, sha256.Size)
+
+	allocs := testing.AllocsPerRun(50, func() {
+		chunk, err = chunker.NextDigest(dst)
+		if err != nil {
+			t.Fatal(err)
+		}
+	})
+
+	want := sha256.Sum256(chunk.Data)
+	if !bytes.Equal(chunk.Digest, want[:]) {
+		t.Errorf("NextDigest mismatch: got %x, want %x", chunk.Digest, want)
+	}
+
+	if allocs != 0 {
+		t.Errorf("NextDigest allocated %.1f times per call, want 0", allocs)
+	}
+}
+
+// TestChunkerProcess tests the callback-based streaming API.
+func TestChunkerProcess(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithTargetSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var rebuilt []byte
+
+	err = chunker.Process(func(chunk fastcdc.Chunk) error {
+		rebuilt = append(rebuilt, chunk.Data...)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(rebuilt, data) {
+		t.Error("Processed chunks do not reconstruct the input")
+	}
+
+	// Callback errors stop processing and are propagated
+	chunker.Reset(bytes.NewReader(data))
+
+	calls := 0
+
+	err = chunker.Process(func(fastcdc.Chunk) error {
+		calls++
+
+		return io.ErrShortWrite
+	})
+	if !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Expected callback error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("Callback called %d times after error, want 1", calls)
+	}
+}
+
+// TestChunkerConfigAccessors verifies that the effective configuration is exposed.
+func TestChunkerConfigAccessors(t *testing.T) {
+	t.Parallel()
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(nil), fastcdc.WithTargetSize(32*1024), fastcdc.WithNormalization(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chunker.MinSize() != fastcdc.DefaultMinSize {
+		t.Errorf("MinSize() = %d, want %d", chunker.MinSize(), fastcdc.DefaultMinSize)
+	}
+
+	if chunker.TargetSize() != 32*1024 {
+		t.Errorf("TargetSize() = %d, want %d", chunker.TargetSize(), 32*1024)
+	}
+
+	if chunker.MaxSize() != fastcdc.DefaultMaxSize {
+		t.Errorf("MaxSize() = %d, want %d", chunker.MaxSize(), fastcdc.DefaultMaxSize)
+	}
+
+	// normSize = minSize + (targetSize - minSize) >> normLevel
+	if want := uint32(16*1024 + (16*1024)>>1); chunker.NormSize() != want {
+		t.Errorf("NormSize() = %d, want %d", chunker.NormSize(), want)
+	}
+
+	if chunker.NormLevel() != 1 {
+		t.Errorf("NormLevel() = %d, want 1", chunker.NormLevel())
+	}
+}
+
+// TestChunkerMaxSizeTailMerge verifies that MaxSize bounds the final chunk
+// merged by TailMerge.
+func TestChunkerMaxSizeTailMerge(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(3*4096+100, 1294)
+	c := mustChunker(t, bytes.NewReader(data), fastcdc.WithFixedSize(4096), fastcdc.WithTailPolicy(fastcdc.TailMerge))
+
+	if want := uint32(2*4096 - 1); c.MaxSize() != want {
+		t.Errorf("MaxSize() = %d, want %d", c.MaxSize(), want)
+	}
+
+	var largest uint32
+
+	for chunk, err := range c.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		largest = max(largest, chunk.Length)
+	}
+
+	if largest != 4096+100 || largest > c.MaxSize() {
+		t.Errorf("Largest chunk has %d bytes, want %d within MaxSize() %d", largest, 4096+100, c.MaxSize())
+	}
+}
+
+// TestWithAverageSize verifies that WithAverageSize is a synonym for WithTargetSize.
+func TestWithAverageSize(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name string
+		opts []fastcdc.Option
+		want uint32
+	}{
+		{"average only", []fastcdc.Option{fastcdc.WithAverageSize(32 * 1024)}, 32 * 1024},
+		{"target then average", []fastcdc.Option{fastcdc.WithTargetSize(128 * 1024), fastcdc.WithAverageSize(32 * 1024)}, 32 * 1024},
+		{"average then target", []fastcdc.Option{fastcdc.WithAverageSize(32 * 1024), fastcdc.WithTargetSize(128 * 1024)}, 128 * 1024},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if core.TargetSize() != tt.want {
+				t.Errorf("TargetSize() = %d, want %d", core.TargetSize(), tt.want)
+			}
+		})
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithAverageSize(0)); !errors.Is(err, fastcdc.ErrInvalidTargetSize) {
+		t.Errorf("Expected ErrInvalidTargetSize, got %v", err)
+	}
+}
+
+// TestNewChunkerFromBytes verifies zero-copy chunking of in-memory data.
+func TestNewChunkerFromBytes(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 1024*1024+17)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	original := bytes.Clone(data)
+
+	streaming, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithTargetSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunkerFromBytes(data, fastcdc.WithTargetSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for {
+		want, wantErr := streaming.Next()
+		got, err := chunker.Next()
+
+		if !errors.Is(err, wantErr) {
+			t.Fatalf("Error mismatch: %v vs %v", err, wantErr)
+		}
+
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got.Offset != want.Offset || got.Length != want.Length || got.Hash != want.Hash {
+			t.Fatalf("Chunk mismatch: %+v vs %+v", got, want)
+		}
+
+		// Chunk data must be a sub-slice of the input, not a copy
+		if &got.Data[0] != &data[got.Offset] {
+			t.Fatalf("Chunk at offset %d does not point into the input", got.Offset)
+		}
+	}
+
+	if !bytes.Equal(data, original) {
+		t.Error("Input data was modified")
+	}
+
+	// Reset switches to a reader without touching the input
+	chunker.Reset(bytes.NewReader(make([]byte, 1024*1024)))
+
+	for _, err := range chunker.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !bytes.Equal(data, original) {
+		t.Error("Input data was modified after Reset")
+	}
+}
+
+// TestFindBoundaryNotFoundConsumesAll verifies that when no boundary is found,
+// boundary equals len(data), including inside the minSize skip region.
+func TestFindBoundaryNotFoundConsumesAll(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 256*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithMinSize(16*1024), fastcdc.WithTargetSize(64*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Segments ending before, exactly at, and past minSize
+	for _, sizes := range [][]int{{100}, {100, 200}, {16 * 1024}, {10 * 1024, 6 * 1024}, {15 * 1024, 2 * 1024}} {
+		core.Reset()
+
+		offset := 0
+
+		for _, size := range sizes {
+			boundary, _, found := core.FindBoundary(data[offset : offset+size])
+			if found {
+				break
+			}
+
+			if boundary != size {
+				t.Errorf("segments %v: not found with boundary %d, want %d", sizes, boundary, size)
+			}
+
+			offset += size
+
+			if core.Position() != uint32(offset) { //nolint:gosec // G115
+				t.Errorf("segments %v: Position() = %d, want %d", sizes, core.Position(), offset)
+			}
+		}
+	}
+
+	core.Reset()
+
+	if boundary, _, found := core.FindBoundary(nil); found || boundary != 0 {
+		t.Errorf("Empty data: (%d, %v), want (0, false)", boundary, found)
+	}
+}
+
+// TestWithTable verifies that a custom Gear table is installed verbatim.
+func TestWithTable(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 512*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	boundaries := func(opts ...fastcdc.Option) []int {
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var result []int
+
+		for offset := 0; offset < len(data); {
+			boundary, _, found := core.FindBoundary(data[offset:])
+			if !found {
+				break
+			}
+
+			offset += boundary
+			result = append(result, offset)
+
+			core.Reset()
+		}
+
+		return result
+	}
+
+	var table [256]uint64
+	for i := range table {
+		table[i] = uint64(i+1) * 0x9e3779b97f4a7c15
+	}
+
+	custom := boundaries(fastcdc.WithTable(table))
+	if slices.Equal(custom, boundaries()) {
+		t.Error("Custom table produced the default boundaries")
+	}
+
+	if !slices.Equal(custom, boundaries(fastcdc.WithTable(table))) {
+		t.Error("Custom table is not deterministic")
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithTable([256]uint64{})); !errors.Is(err, fastcdc.ErrInvalidTable) {
+		t.Errorf("Expected ErrInvalidTable, got %v", err)
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithTable(table), fastcdc.WithSeed(1)); !errors.Is(err, fastcdc.ErrTableWithSeed) {
+		t.Errorf("Expected ErrTableWithSeed, got %v", err)
+	}
+}
+
+// TestGenerateTable verifies the exported tables against WithSeed.
+func TestGenerateTable(t *testing.T) {
+	t.Parallel()
+
+	if fastcdc.GenerateTable(0) != fastcdc.DefaultTable {
+		t.Error("GenerateTable(0) differs from DefaultTable")
+	}
+
+	// Pin both ends of the default table for cross-implementation checks
+	if fastcdc.DefaultTable[0] != 0x5c95c078 || fastcdc.DefaultTable[255] != 0x46c3d6f3 {
+		t.Errorf("DefaultTable = [%#x ... %#x]", fastcdc.DefaultTable[0], fastcdc.DefaultTable[255])
+	}
+
+	const seed = 0x0123456789abcdef
+
+	table := fastcdc.GenerateTable(seed)
+	for i, v := range table {
+		if v != fastcdc.DefaultTable[i]^seed {
+			t.Fatalf("GenerateTable(%#x)[%d] = %#x, want %#x", uint64(seed), i, v, fastcdc.DefaultTable[i]^seed)
+		}
+	}
+
+	data := randBytes(1024*1024, 1295)
+
+	seeded, err := fastcdc.GoldenBoundaries(data, fastcdc.WithSeed(seed))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	installed, err := fastcdc.GoldenBoundaries(data, fastcdc.WithTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(seeded, installed) {
+		t.Error("WithTable(GenerateTable(seed)) differs from WithSeed(seed)")
+	}
+}
+
+// TestGearHash checks GearHash against Chunk.Hash, which hashes the bytes past
+// minSize, or the whole chunk with WithHashFromStart.
+func TestGearHash(t *testing.T) {
+	t.Parallel()
+
+	if got := fastcdc.GearHash(fastcdc.DefaultTable, nil); got != 0 {
+		t.Errorf("GearHash of no data = %#x, want 0", got)
+	}
+
+	if got, want := fastcdc.GearHash(fastcdc.DefaultTable, []byte{0, 255}),
+		fastcdc.DefaultTable[0]<<1+fastcdc.DefaultTable[255]; got != want {
+		t.Errorf("GearHash of two bytes = %#x, want %#x", got, want)
+	}
+
+	const minSize = 4 * 1024
+
+	data := randBytes(1024*1024, 1329)
+	table := fastcdc.GenerateTable(1329)
+
+	for _, fromStart := range []bool{false, true} {
+		opts := []fastcdc.Option{fastcdc.WithTable(table), fastcdc.WithMinSize(minSize), fastcdc.WithTargetSize(16 * 1024)}
+		if fromStart {
+			opts = append(opts, fastcdc.WithHashFromStart())
+		}
+
+		err := mustChunker(t, bytes.NewReader(data), opts...).Process(func(chunk fastcdc.Chunk) error {
+			if chunk.Last && len(chunk.Data) <= minSize {
+				return nil // Not hashed
+			}
+
+			hashed := chunk.Data[minSize:]
+			if fromStart {
+				hashed = chunk.Data
+			}
+
+			if got := fastcdc.GearHash(table, hashed); got != chunk.Hash {
+				t.Fatalf("from start %v: chunk at %d: GearHash = %#x, Chunk.Hash = %#x", fromStart, chunk.Offset,
+					got, chunk.Hash)
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+// TestChunkWriteTo tests the io.WriterTo implementation.
+func TestChunkWriteTo(t *testing.T) {
+	t.Parallel()
+
+	chunk := fastcdc.Chunk{Length: 11, Data: []byte("hello world")}
+
+	var buf bytes.Buffer
+
+	n, err := chunk.WriteTo(&buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n != 11 || buf.String() != "hello world" {
+		t.Errorf("WriteTo wrote %d bytes %q", n, buf.String())
+	}
+
+	// Write errors are propagated
+	pr, pw := io.Pipe()
+	pr.Close()
+
+	if _, err := chunk.WriteTo(pw); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("Expected io.ErrClosedPipe, got %v", err)
+	}
+}
+
+// TestWithMaxBufferSize tests the ceiling on the internal buffer size.
+func TestWithMaxBufferSize(t *testing.T) {
+	t.Parallel()
+
+	// maxSize raises the buffer above the default ceiling
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(1024 * 1024),
+		fastcdc.WithTargetSize(32 * 1024 * 1024),
+		fastcdc.WithMaxSize(128 * 1024 * 1024),
+	}
+
+	if _, err := fastcdc.NewChunker(bytes.NewReader(nil), opts...); !errors.Is(err, fastcdc.ErrBufferTooLarge) {
+		t.Errorf("Expected ErrBufferTooLarge, got %v", err)
+	}
+
+	// The low-level API allocates no buffer and is unaffected
+	if _, err := fastcdc.NewChunkerCore(opts...); err != nil {
+		t.Errorf("NewChunkerCore failed: %v", err)
+	}
+
+	if _, err := fastcdc.NewChunker(bytes.NewReader(nil), fastcdc.WithBufferSize(2*1024*1024),
+		fastcdc.WithMaxBufferSize(1024*1024)); !errors.Is(err, fastcdc.ErrBufferTooLarge) {
+		t.Errorf("Expected ErrBufferTooLarge, got %v", err)
+	}
+
+	if _, err := fastcdc.NewChunker(bytes.NewReader(nil), fastcdc.WithMaxBufferSize(0)); !errors.Is(err, fastcdc.ErrInvalidBufferSize) {
+		t.Errorf("Expected ErrInvalidBufferSize, got %v", err)
+	}
+}
+
+// TestRecommendedBufferSize tests the recommended and auto-adjusted buffer sizes.
+func TestRecommendedBufferSize(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		name string
+		opts []fastcdc.Option
+		want int
+	}{
+		{"defaults", nil, 4 * fastcdc.DefaultMaxSize},
+		{"small chunks", []fastcdc.Option{fastcdc.WithMinSize(1024), fastcdc.WithTargetSize(4096), fastcdc.WithMaxSize(8192)},
+			fastcdc.DefaultBufferSize},
+		{"capped", []fastcdc.Option{fastcdc.WithMaxBufferSize(512*1024 + 100)}, 512*1024 + 100},
+		{"lookahead above the cap", []fastcdc.Option{fastcdc.WithMaxBufferSize(1024)}, fastcdc.DefaultMaxSize + 1},
+	} {
+		if got, err := fastcdc.RecommendedBufferSize(tt.opts...); err != nil || got != tt.want {
+			t.Errorf("%s: RecommendedBufferSize() = %d, %v, want %d", tt.name, got, err, tt.want)
+		}
+	}
+
+	if _, err := fastcdc.RecommendedBufferSize(fastcdc.WithTargetSize(0)); !errors.Is(err, fastcdc.ErrInvalidTargetSize) {
+		t.Errorf("Expected ErrInvalidTargetSize, got %v", err)
+	}
+
+	// A buffer too small for a maximum-size chunk is replaced with the
+	// recommended size, which the first refill fills
+	data := randBytes(8*1024*1024, 1299)
+	c := mustChunker(t, bytes.NewReader(data), fastcdc.WithMaxSize(1024*1024), fastcdc.WithBufferSize(1024))
+
+	if _, err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if read := c.Offset() + uint64(c.Buffered()); read != 4*1024*1024 { //nolint:gosec // G115
+		t.Errorf("first refill read %d bytes, want %d", read, 4*1024*1024)
+	}
+}
+
+// TestChunkReader tests reading chunk data through Chunk.Reader.
+func TestChunkReader(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(256*1024, 1264)
+
+	c, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Consume the reader before advancing
+		if _, err := io.Copy(&out, chunk.Reader()); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Error("Data read through Chunk.Reader does not match input")
+	}
+}
+
+// TestFindAllBoundaries tests that FindAllBoundaries matches a FindBoundary loop.
+func TestFindAllBoundaries(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1265)
+
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithMinSize(2*1024), fastcdc.WithTargetSize(8*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type cut struct {
+		offset, length int
+		hash           uint64
+	}
+
+	var want []cut
+
+	offset := 0
+
+	for offset < len(data) {
+		boundary, hash, found := core.FindBoundary(data[offset:])
+		if !found {
+			break
+		}
+
+		want = append(want, cut{offset, boundary, hash})
+		offset += boundary
+
+		core.Reset()
+	}
+
+	core.Reset()
+
+	var got []cut
+
+	tail := core.FindAllBoundaries(data, func(offset, length int, hash uint64) {
+		got = append(got, cut{offset, length, hash})
+	})
+
+	if tail != len(data)-offset {
+		t.Errorf("Tail = %d, want %d", tail, len(data)-offset)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("FindAllBoundaries reported %d chunks, want %d matching FindBoundary", len(got), len(want))
+	}
+
+	if core.Position() != uint32(tail) { //nolint:gosec // G115
+		t.Errorf("Position = %d, want tail %d", core.Position(), tail)
+	}
+}
+
+// TestAppendBoundaries tests that AppendBoundaries agrees with FindAllBoundaries.
+func TestAppendBoundaries(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1290)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024)}
+
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []int
+
+	tail := core.FindAllBoundaries(data, func(offset, length int, _ uint64) {
+		want = append(want, offset+length)
+	})
+
+	core.Reset()
+
+	// Existing elements of dst are kept
+	got, consumed := core.AppendBoundaries([]int{-1}, data)
+
+	if consumed != len(data)-tail {
+		t.Errorf("consumed = %d, want %d", consumed, len(data)-tail)
+	}
+
+	if !slices.Equal(got, append([]int{-1}, want...)) {
+		t.Errorf("AppendBoundaries reported %d boundaries, want %d", len(got)-1, len(want))
+	}
+
+	// The tail continues into the next call
+	core.Reset()
+
+	half := len(data) / 2
+	first, consumed := core.AppendBoundaries(nil, data[:half])
+	second, _ := core.AppendBoundaries(nil, data[half:])
+
+	for _, b := range second {
+		first = append(first, half+b)
+	}
+
+	if consumed == half || !slices.Equal(first, want) {
+		t.Errorf("split input reported %d boundaries, want %d", len(first), len(want))
+	}
+
+	// Zeros never match the mask, so every chunk is cut at maxSize
+	core.Reset()
+
+	zeros := make([]byte, 10*int(core.MaxSize())+1)
+	got, consumed = core.AppendBoundaries(got[:0], zeros)
+
+	if len(got) != 10 || consumed != 10*int(core.MaxSize()) || got[0] != int(core.MaxSize()) {
+		t.Errorf("zeros: got boundaries %v, consumed %d, want every %d bytes", got, consumed, core.MaxSize())
+	}
+}
+
+//nolint:paralleltest // AllocsPerRun cannot be used in parallel tests
+func TestAppendBoundariesAllocs(t *testing.T) {
+	data := randBytes(1024*1024, 1290)
+	dst := make([]int, 0, 1024)
+
+	core, err := fastcdc.NewChunkerCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		core.Reset()
+		dst, _ = core.AppendBoundaries(dst[:0], data)
+	})
+	if allocs != 0 {
+		t.Errorf("AppendBoundaries allocated %.1f times per call, want 0", allocs)
+	}
+}
+
+// TestWithCopyData tests that copied chunk data survives subsequent Next calls.
+func TestWithCopyData(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(512*1024, 1266)
+
+	c, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithBufferSize(256*1024), fastcdc.WithCopyData())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []fastcdc.Chunk
+
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chunks = append(chunks, chunk)
+	}
+
+	for _, chunk := range chunks {
+		if !bytes.Equal(chunk.Data, data[chunk.Offset:chunk.Offset+uint64(chunk.Length)]) {
+			t.Fatalf("Retained chunk at offset %d was overwritten", chunk.Offset)
+		}
+	}
+}
+
+// TestNewChunkerFromConfig tests that chunkers built from a Config match NewChunker.
+func TestNewChunkerFromConfig(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithMinSize(0)); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("Expected ErrInvalidMinSize, got %v", err)
+	}
+
+	opts := []fastcdc.Option{fastcdc.WithTargetSize(32 * 1024), fastcdc.WithSeed(1267)}
+
+	cfg, err := fastcdc.NewConfig(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randBytes(1024*1024, 1267)
+
+	want, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The same Config can be reused for several chunkers
+	for range 2 {
+		got := fastcdc.NewChunkerFromConfig(bytes.NewReader(data), cfg)
+		want.Reset(bytes.NewReader(data))
+
+		for {
+			g, gErr := got.Next()
+			w, wErr := want.Next()
+
+			if !errors.Is(gErr, wErr) {
+				t.Fatalf("Error mismatch: got %v, want %v", gErr, wErr)
+			}
+
+			if wErr != nil {
+				break
+			}
+
+			if g.Offset != w.Offset || g.Length != w.Length || g.Hash != w.Hash {
+				t.Fatalf("Chunk mismatch: got %d+%d, want %d+%d", g.Offset, g.Length, w.Offset, w.Length)
+			}
+		}
+	}
+}
+
+// TestWithRandomSeed tests that random seeds differ and can be reproduced with WithSeed.
+func TestWithRandomSeed(t *testing.T) {
+	t.Parallel()
+
+	a, err := fastcdc.NewChunkerCore(fastcdc.WithRandomSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := fastcdc.NewChunkerCore(fastcdc.WithRandomSeed())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if a.Seed() == 0 || a.Seed() == b.Seed() {
+		t.Errorf("Expected distinct non-zero seeds, got %d and %d", a.Seed(), b.Seed())
+	}
+
+	replay, err := fastcdc.NewChunkerCore(fastcdc.WithSeed(a.Seed()))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	data := randBytes(1024*1024, 1268)
+
+	boundary, hash, _ := a.FindBoundary(data)
+	replayBoundary, replayHash, _ := replay.FindBoundary(data)
+
+	if boundary != replayBoundary || hash != replayHash {
+		t.Errorf("WithSeed(Seed()) found %d/%x, want %d/%x", replayBoundary, replayHash, boundary, hash)
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithTable([256]uint64{1}), fastcdc.WithRandomSeed()); !errors.Is(err, fastcdc.ErrTableWithSeed) {
+		t.Errorf("Expected ErrTableWithSeed, got %v", err)
+	}
+}
+
+// TestNilReader tests that a nil reader is reported instead of panicking.
+func TestNilReader(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fastcdc.NewChunker(nil); !errors.Is(err, fastcdc.ErrNilReader) {
+		t.Errorf("Expected ErrNilReader, got %v", err)
+	}
+
+	cfg, err := fastcdc.NewConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c := fastcdc.NewChunkerFromConfig(nil, cfg)
+	if _, err := c.Next(); !errors.Is(err, fastcdc.ErrNilReader) {
+		t.Errorf("Expected ErrNilReader from Next, got %v", err)
+	}
+
+	// Reset with a nil reader, as left behind by ChunkerPool.Put
+	c.Reset(nil)
+
+	if _, err := c.Next(); !errors.Is(err, fastcdc.ErrNilReader) {
+		t.Errorf("Expected ErrNilReader after Reset(nil), got %v", err)
+	}
+}
+
+// TestWithMinChunkCount tests that small inputs are split into more chunks.
+func TestWithMinChunkCount(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithMinChunkCount(0)); !errors.Is(err, fastcdc.ErrInvalidMinChunkCount) {
+		t.Errorf("Expected ErrInvalidMinChunkCount, got %v", err)
+	}
+
+	chunkOffsets := func(c *fastcdc.Chunker) []uint64 {
+		var offsets []uint64
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			offsets = append(offsets, chunk.Offset)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return offsets
+	}
+
+	small := randBytes(30*1024, 1271)
+
+	c, err := fastcdc.NewChunker(bytes.NewReader(small), fastcdc.WithMinChunkCount(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got := chunkOffsets(c)
+	if len(got) < 4 {
+		t.Errorf("Got %d chunks for a %d byte input, want at least 4", len(got), len(small))
+	}
+
+	// The in-memory and bufio paths split identically
+	fromBytes, err := fastcdc.NewChunkerFromBytes(small, fastcdc.WithMinChunkCount(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if offsets := chunkOffsets(fromBytes); !slices.Equal(offsets, got) {
+		t.Errorf("NewChunkerFromBytes offsets %v, want %v", offsets, got)
+	}
+
+	c.Reset(bufio.NewReaderSize(bytes.NewReader(small), 1024*1024))
+
+	if offsets := chunkOffsets(c); !slices.Equal(offsets, got) {
+		t.Errorf("bufio.Reader offsets %v, want %v", offsets, got)
+	}
+
+	// Large inputs, including after Reset from a small one, chunk as usual
+	large := randBytes(2*1024*1024, 1271)
+
+	plain, err := fastcdc.NewChunker(bytes.NewReader(large))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reset(bytes.NewReader(large))
+
+	if offsets, want := chunkOffsets(c), chunkOffsets(plain); !slices.Equal(offsets, want) {
+		t.Errorf("Large input chunked into %d chunks, want %d", len(offsets), len(want))
+	}
+}
+
+// TestWithBoundaryValue tests that natural boundaries satisfy (fp & mask) == value & mask.
+func TestWithBoundaryValue(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1273)
+
+	// targetSize 8 KiB gives 13 mask bits, the small mask has one bit fewer
+	const maskL, maskS = 1<<13 - 1, 1<<12 - 1
+
+	var defaultCuts []int
+
+	for _, value := range []uint64{0, ^uint64(0), 0x5a5a5a5a5a5a5a5a} {
+		core, err := fastcdc.NewChunkerCore(
+			fastcdc.WithMinSize(2*1024),
+			fastcdc.WithTargetSize(8*1024),
+			fastcdc.WithMaxSize(64*1024),
+			fastcdc.WithBoundaryValue(value),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var cuts []int
+
+		for offset := 0; offset < len(data); {
+			boundary, hash, found := core.FindBoundary(data[offset:])
+			if !found {
+				break
+			}
+
+			mask := uint64(maskL)
+			if boundary <= int(core.NormSize()) {
+				mask = maskS
+			}
+
+			if boundary < int(core.MaxSize()) && hash&mask != value&mask {
+				t.Fatalf("Value %x: boundary at %d has fp & mask = %x, want %x", value, offset+boundary, hash&mask, value&mask)
+			}
+
+			offset += boundary
+			cuts = append(cuts, offset)
+
+			core.Reset()
+		}
+
+		if value == 0 {
+			defaultCuts = cuts
+		} else if slices.Equal(cuts, defaultCuts) {
+			t.Errorf("Value %x produced the same boundaries as the default", value)
+		}
+	}
+}
+
+// TestReconfigure tests switching options between streams and mid-stream.
+func TestReconfigure(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1274)
+
+	offsets := func(c *fastcdc.Chunker) []uint64 {
+		var out []uint64
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			out = append(out, chunk.Offset)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return out
+	}
+
+	large := []fastcdc.Option{
+		fastcdc.WithMinSize(256 * 1024),
+		fastcdc.WithTargetSize(1024 * 1024),
+		fastcdc.WithMaxSize(4 * 1024 * 1024),
+	}
+
+	fresh, err := fastcdc.NewChunker(bytes.NewReader(data), large...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := offsets(fresh)
+
+	// Between streams, growing the buffer
+	c, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	offsets(c)
+
+	if err := c.Reconfigure(large...); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reset(bytes.NewReader(data))
+
+	if got := offsets(c); !slices.Equal(got, want) {
+		t.Errorf("Reconfigured chunker produced %d chunks, want %d", len(got), len(want))
+	}
+
+	if c.TargetSize() != 1024*1024 {
+		t.Errorf("TargetSize = %d, want %d", c.TargetSize(), 1024*1024)
+	}
+
+	// Invalid options leave the chunker unchanged
+	if err := c.Reconfigure(fastcdc.WithMinSize(0)); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("Expected ErrInvalidMinSize, got %v", err)
+	}
+
+	// Mid-stream: chunks after the switch follow the new options, no bytes are lost
+	for _, r := range []io.Reader{bytes.NewReader(data), bufio.NewReaderSize(bytes.NewReader(data), 1024*1024)} {
+		c, err := fastcdc.NewChunker(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		first, err := c.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := c.Reconfigure(large...); err != nil {
+			t.Fatal(err)
+		}
+
+		total := uint64(first.Length)
+
+		err = c.Process(func(chunk fastcdc.Chunk) error {
+			if chunk.Offset != total {
+				t.Fatalf("Chunk offset %d, want %d", chunk.Offset, total)
+			}
+
+			if !bytes.Equal(chunk.Data, data[chunk.Offset:chunk.Offset+uint64(chunk.Length)]) {
+				t.Fatalf("Chunk data at offset %d does not match input", chunk.Offset)
+			}
+
+			total += uint64(chunk.Length)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if total != uint64(len(data)) {
+			t.Errorf("Chunks covered %d bytes, want %d", total, len(data))
+		}
+	}
+}
+
+// TestMaxSizePlatformLimit tests that maxSize is limited to what fits in an int.
+// Run with GOARCH=386 to exercise the 32-bit limit.
+func TestMaxSizePlatformLimit(t *testing.T) {
+	t.Parallel()
+
+	const huge uint32 = math.MaxInt32 + 1
+
+	_, err := fastcdc.NewChunkerCore(
+		fastcdc.WithMinSize(1024*1024),
+		fastcdc.WithTargetSize(64*1024*1024),
+		fastcdc.WithMaxSize(huge),
+	)
+
+	if math.MaxInt == math.MaxInt32 {
+		if !errors.Is(err, fastcdc.ErrMaxSizeTooLarge) {
+			t.Errorf("Expected ErrMaxSizeTooLarge on a 32-bit platform, got %v", err)
+		}
+
+		return
+	}
+
+	if err != nil {
+		t.Errorf("maxSize %d should be valid on a 64-bit platform, got %v", huge, err)
+	}
+}
+
+// TestWithMasks tests explicitly configured masks.
+func TestWithMasks(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1277)
+
+	sizes := []fastcdc.Option{
+		fastcdc.WithMinSize(2 * 1024),
+		fastcdc.WithTargetSize(8 * 1024),
+		fastcdc.WithMaxSize(64 * 1024),
+	}
+
+	boundaries := func(opts ...fastcdc.Option) []int {
+		core, err := fastcdc.NewChunkerCore(append(slices.Clone(sizes), opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var cuts []int
+
+		tail := core.FindAllBoundaries(data, func(offset, length int, hash uint64) {
+			cuts = append(cuts, offset+length)
+		})
+
+		return append(cuts, len(data)-tail)
+	}
+
+	// The masks derived from an 8 KiB target reproduce the default boundaries
+	if got, want := boundaries(fastcdc.WithMasks(1<<12-1, 1<<13-1)), boundaries(); !slices.Equal(got, want) {
+		t.Errorf("Explicit default masks produced %d boundaries, want %d", len(got), len(want))
+	}
+
+	// Spread masks with the same number of bits, as used by some implementations
+	const spreadS, spreadL = 0x0000_0000_0353_0000, 0x0000_0000_0353_4000
+
+	if got, def := boundaries(fastcdc.WithMasks(spreadS, spreadL)), boundaries(); slices.Equal(got, def) {
+		t.Error("Spread masks produced the default boundaries")
+	}
+
+	for _, tt := range []struct {
+		name         string
+		maskS, maskL uint64
+	}{
+		{"zero large mask", 0, 0},
+		{"small mask has more bits", 0xff, 0x0f},
+	} {
+		if _, err := fastcdc.NewChunkerCore(fastcdc.WithMasks(tt.maskS, tt.maskL)); !errors.Is(err, fastcdc.ErrInvalidMasks) {
+			t.Errorf("%s: expected ErrInvalidMasks, got %v", tt.name, err)
+		}
+	}
+}
+
+// TestWithTargetBits tests setting the mask bits independently of targetSize.
+func TestWithTargetBits(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1332)
+
+	// A 48 KiB target is rounded down to the 15 bits of 32 KiB
+	sizes := []fastcdc.Option{
+		fastcdc.WithMinSize(8 * 1024),
+		fastcdc.WithTargetSize(48 * 1024),
+		fastcdc.WithMaxSize(256 * 1024),
+	}
+
+	boundaries := func(opts ...fastcdc.Option) []int {
+		core, err := fastcdc.NewChunkerCore(append(slices.Clone(sizes), opts...)...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		boundaries, _ := core.AppendBoundaries(nil, data)
+
+		return boundaries
+	}
+
+	if got, want := boundaries(fastcdc.WithTargetBits(15)), boundaries(); !slices.Equal(got, want) {
+		t.Errorf("15 bits produced %d boundaries, want the %d of the derived masks", len(got), len(want))
+	}
+
+	sixteen := boundaries(fastcdc.WithTargetBits(16))
+	if want := boundaries(fastcdc.WithMasks(1<<15-1, 1<<16-1)); !slices.Equal(sixteen, want) {
+		t.Errorf("16 bits produced %d boundaries, want the %d of the equivalent masks", len(sixteen), len(want))
+	}
+
+	if len(sixteen) >= len(boundaries()) {
+		t.Errorf("16 bits produced %d boundaries, not fewer than the %d of 15 bits", len(sixteen), len(boundaries()))
+	}
+
+	for _, tt := range []struct {
+		opts []fastcdc.Option
+		want error
+	}{
+		{[]fastcdc.Option{fastcdc.WithTargetBits(0)}, fastcdc.ErrInvalidTargetBits},
+		{[]fastcdc.Option{fastcdc.WithTargetBits(31)}, fastcdc.ErrInvalidTargetBits},
+		{[]fastcdc.Option{fastcdc.WithTargetBits(16), fastcdc.WithMasks(1<<15-1, 1<<16-1)}, fastcdc.ErrTargetBitsWithMasks},
+		{[]fastcdc.Option{fastcdc.WithTargetBits(16), fastcdc.WithFixedSize(4096)}, fastcdc.ErrFixedSizeConflict},
+		{[]fastcdc.Option{fastcdc.WithTargetBits(1)}, fastcdc.ErrInvalidNormStrength},
+	} {
+		if _, err := fastcdc.NewConfig(tt.opts...); !errors.Is(err, tt.want) {
+			t.Errorf("got %v, want %v", err, tt.want)
+		}
+	}
+}
+
+// TestWithNormSize tests setting the normalization boundary directly.
+func TestWithNormSize(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1292)
+
+	// The default normSize is 16 KiB + (64 KiB - 16 KiB) / 4 = 28 KiB
+	want, err := fastcdc.GoldenBoundaries(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fastcdc.GoldenBoundaries(data, fastcdc.WithNormSize(28*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("explicit default normSize produced %d boundaries, want %d", len(got), len(want))
+	}
+
+	// Beyond targetSize, the small mask applies to most of the chunk
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithNormSize(100 * 1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if core.NormSize() != 100*1024 {
+		t.Errorf("NormSize() = %d, want %d", core.NormSize(), 100*1024)
+	}
+
+	got, err = fastcdc.GoldenBoundaries(data, fastcdc.WithNormSize(100*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) <= len(want) {
+		t.Errorf("normSize 100 KiB produced %d chunks, want more than the default %d", len(got), len(want))
+	}
+
+	for _, size := range []uint32{0, fastcdc.DefaultMinSize, fastcdc.DefaultMaxSize} {
+		if _, err := fastcdc.NewChunkerCore(fastcdc.WithNormSize(size)); !errors.Is(err, fastcdc.ErrInvalidNormSize) {
+			t.Errorf("normSize %d: expected ErrInvalidNormSize, got %v", size, err)
+		}
+	}
+}
+
+func TestWithNormRegions(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(8*1024*1024, 1311)
+
+	want, err := fastcdc.GoldenBoundaries(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if got, err := fastcdc.GoldenBoundaries(data, fastcdc.WithNormRegions(2)); err != nil || !slices.Equal(got, want) {
+		t.Errorf("two regions produced %d boundaries (%v), want the default %d", len(got), err, len(want))
+	}
+
+	// Reference: regions start every normSize-minSize bytes from minSize, with
+	// one mask bit more each, up to the 16 bits of the default targetSize
+	const minSize, normSize, maxSize = 16 * 1024, 28 * 1024, 256 * 1024
+
+	var (
+		reference []int
+		fp        uint64
+		start     int
+	)
+
+	for i, b := range data {
+		pos := i - start // Position of b within the chunk
+		if pos < minSize {
+			continue
+		}
+
+		fp = (fp << 1) + fastcdc.DefaultTable[b]
+		region := min((pos-minSize)/(normSize-minSize), 2)
+
+		if fp&(1<<(14+region)-1) == 0 || pos+1 >= maxSize {
+			reference = append(reference, i+1)
+			fp, start = 0, i+1
+		}
+	}
+
+	if start < len(data) {
+		reference = append(reference, len(data))
+	}
+
+	got, err := fastcdc.GoldenBoundaries(data, fastcdc.WithNormRegions(3))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(got, reference) {
+		t.Errorf("three regions produced %d boundaries, reference %d", len(got), len(reference))
+	}
+
+	// Split reads resume within the right region
+	var streamed []int
+
+	c := mustChunker(t, iotest.HalfReader(bytes.NewReader(data)), fastcdc.WithNormRegions(3), fastcdc.WithBufferSize(300*1024))
+
+	err = c.Process(func(chunk fastcdc.Chunk) error {
+		streamed = append(streamed, int(chunk.Offset)+int(chunk.Length))
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(streamed, reference) {
+		t.Errorf("streaming with three regions produced %d boundaries, reference %d", len(streamed), len(reference))
+	}
+
+	// More aggressive early regions narrow the spread of chunk sizes
+	stats := func(n int) fastcdc.ChunkStats {
+		c := mustChunker(t, bytes.NewReader(data), fastcdc.WithNormRegions(n), fastcdc.WithStats(true))
+		if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+
+		return c.Stats()
+	}
+
+	two, three := stats(2), stats(3)
+	t.Logf("Chunk sizes: 2 regions mean %.0f stddev %.0f, 3 regions mean %.0f stddev %.0f",
+		two.Mean, two.StdDev, three.Mean, three.StdDev)
+
+	if three.StdDev >= two.StdDev {
+		t.Errorf("stddev %.0f with 3 regions, want below %.0f with 2", three.StdDev, two.StdDev)
+	}
+
+	for _, opts := range [][]fastcdc.Option{
+		{fastcdc.WithNormRegions(1)},
+		{fastcdc.WithNormRegions(9)},
+		{fastcdc.WithNormRegions(3), fastcdc.WithMasks(0xff, 0xffff)},
+	} {
+		if _, err := fastcdc.NewChunkerCore(opts...); !errors.Is(err, fastcdc.ErrInvalidNormRegions) {
+			t.Errorf("expected ErrInvalidNormRegions, got %v", err)
+		}
+	}
+
+	_, err = fastcdc.NewChunkerCore(fastcdc.WithNormRegions(3), fastcdc.WithNormalizationStrength(8))
+	if !errors.Is(err, fastcdc.ErrInvalidNormStrength) {
+		t.Errorf("expected ErrInvalidNormStrength, got %v", err)
+	}
+}
+
+// TestChunkLast tests that exactly the final chunk has Last set.
+func TestChunkLast(t *testing.T) {
+	t.Parallel()
+
+	opts := []fastcdc.Option{fastcdc.WithMinSize(64), fastcdc.WithTargetSize(256), fastcdc.WithMaxSize(1024)}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"single chunk", randBytes(100, 1278)},
+		{"multiple chunks", randBytes(64*1024, 1278)},
+		// Zeros never match a mask, so the input ends exactly on a maxSize cut
+		{"ends at maxSize", make([]byte, 4*1024)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			fromBytes, err := fastcdc.NewChunkerFromBytes(tt.data, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for name, c := range map[string]*fastcdc.Chunker{
+				"reader":     mustChunker(t, bytes.NewReader(tt.data), opts...),
+				"bufio":      mustChunker(t, bufio.NewReaderSize(bytes.NewReader(tt.data), 4096), opts...),
+				"from bytes": fromBytes,
+			} {
+				var lasts []uint64
+
+				err := c.Process(func(chunk fastcdc.Chunk) error {
+					if chunk.Last {
+						lasts = append(lasts, chunk.Offset+uint64(chunk.Length))
+					}
+
+					return nil
+				})
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(lasts) != 1 || lasts[0] != uint64(len(tt.data)) {
+					t.Errorf("%s: chunks marked Last end at %v, want only the one ending at %d", name, lasts, len(tt.data))
+				}
+			}
+		})
+	}
+}
+
+func mustChunker(t *testing.T, r io.Reader, opts ...fastcdc.Option) *fastcdc.Chunker {
+	t.Helper()
+
+	c, err := fastcdc.NewChunker(r, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return c
+}
+
+// TestFindBoundaryReader tests that FindBoundaryReader chunks like Chunker.
+func TestFindBoundaryReader(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024+123, 1282)
+
+	core, err := fastcdc.NewChunkerCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := core.FindBoundaryReader(bytes.NewReader(data), make([]byte, 1024)); !errors.Is(err, fastcdc.ErrBufferTooSmall) {
+		t.Errorf("Expected ErrBufferTooSmall, got %v", err)
+	}
+
+	want, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := bytes.NewReader(data)
+	buf := make([]byte, fastcdc.DefaultMaxSize+1)
+
+	for {
+		got, gErr := core.FindBoundaryReader(r, buf)
+		w, wErr := want.Next()
+
+		if !errors.Is(gErr, wErr) {
+			t.Fatalf("Error mismatch: got %v, want %v", gErr, wErr)
+		}
+
+		if wErr != nil {
+			break
+		}
+
+		if got.Offset != w.Offset || got.Length != w.Length || got.Hash != w.Hash || got.Last != w.Last {
+			t.Fatalf("Chunk mismatch: got %d+%d, want %d+%d", got.Offset, got.Length, w.Offset, w.Length)
+		}
+
+		if !bytes.Equal(got.Data, w.Data) {
+			t.Fatalf("Chunk data mismatch at offset %d", got.Offset)
+		}
+	}
+
+	// Reset starts a new stream
+	core.Reset()
+
+	chunk, err := core.FindBoundaryReader(bytes.NewReader(data[:100]), buf)
+	if err != nil || chunk.Offset != 0 || chunk.Length != 100 || !chunk.Last {
+		t.Errorf("After Reset got %d+%d (last %v), %v; want 0+100 (last)", chunk.Offset, chunk.Length, chunk.Last, err)
+	}
+}
+
+//nolint:paralleltest // AllocsPerRun cannot be used in parallel tests
+func TestChunkerCoreClone(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1313)
+	other := randBytes(1024*1024, 1314)
+
+	for _, rolling := range []fastcdc.RollingHash{fastcdc.HashGear, fastcdc.HashRabin} {
+		core, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(rolling), fastcdc.WithMinSize(1024),
+			fastcdc.WithTargetSize(4096), fastcdc.WithMaxSize(16*1024))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Stop within the first chunk, past the start of the Rabin window
+		if _, _, found := core.FindBoundary(data[:1000]); found {
+			t.Fatal("unexpected boundary before minSize")
+		}
+
+		clone := core.Clone()
+		diverged := core.Clone()
+
+		// A clone fed other data leaves the original and other clones unaffected
+		diverged.AppendBoundaries(nil, other)
+
+		want, _ := core.AppendBoundaries(nil, data[1000:])
+		got, _ := clone.AppendBoundaries(nil, data[1000:])
+
+		if !slices.Equal(got, want) || len(want) == 0 {
+			t.Errorf("rolling %d: clone found %d boundaries, original %d", rolling, len(got), len(want))
+		}
+
+		// The prefix counts towards the first chunk of both
+		reference, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(rolling), fastcdc.WithMinSize(1024),
+			fastcdc.WithTargetSize(4096), fastcdc.WithMaxSize(16*1024))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if first, _, _ := reference.FindBoundary(data); first != 1000+want[0] {
+			t.Errorf("rolling %d: first boundary at %d, want %d", rolling, 1000+want[0], first)
+		}
+	}
+}
+
+func TestFindBoundaryReaderAllocs(t *testing.T) {
+	data := randBytes(1024*1024, 1282)
+	r := bytes.NewReader(data)
+	buf := make([]byte, fastcdc.DefaultMaxSize+1)
+
+	core, err := fastcdc.NewChunkerCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	allocs := testing.AllocsPerRun(10, func() {
+		r.Reset(data)
+		core.Reset()
+
+		for {
+			if _, err := core.FindBoundaryReader(r, buf); err != nil {
+				break
+			}
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("FindBoundaryReader allocated %.1f times per stream, want 0", allocs)
+	}
+}
+
+// TestChunkerBuffered tests that Buffered accounts for the read-ahead.
+func TestChunkerBuffered(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1)
+	r := bytes.NewReader(data)
+	c := mustChunker(t, r, fastcdc.WithTargetSize(16*1024), fastcdc.WithMinSize(4*1024))
+
+	if n := c.Buffered(); n != 0 {
+		t.Fatalf("Buffered() = %d before the first chunk, want 0", n)
+	}
+
+	err := c.Process(func(fastcdc.Chunk) error {
+		// Everything read from r is either returned or buffered
+		read := uint64(r.Size()) - uint64(r.Len()) //nolint:gosec // G115
+
+		if got := c.Offset() + uint64(c.Buffered()); got != read { //nolint:gosec // G115
+			t.Fatalf("Offset()+Buffered() = %d, want %d bytes read", got, read)
+		}
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if n := c.Buffered(); n != 0 {
+		t.Errorf("Buffered() = %d at EOF, want 0", n)
+	}
+
+	// Read-ahead stays in a bufio.Reader the chunker peeks into
+	c = mustChunker(t, bufio.NewReaderSize(bytes.NewReader(data), 512*1024), fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithMinSize(4*1024))
+
+	if _, err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := c.Buffered(); n != 0 {
+		t.Errorf("Buffered() = %d with a bufio.Reader, want 0", n)
+	}
+}
+
+func TestChunkerSkip(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1305)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	// chunks returns the remaining chunks, without data
+	chunks := func(c *fastcdc.Chunker) []fastcdc.Chunk {
+		var out []fastcdc.Chunk
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			chunk.Data = nil
+			out = append(out, chunk)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return out
+	}
+
+	inputs := map[string]func() *fastcdc.Chunker{
+		"reader": func() *fastcdc.Chunker { return mustChunker(t, bytes.NewReader(data), opts...) },
+		"bufio": func() *fastcdc.Chunker {
+			return mustChunker(t, bufio.NewReaderSize(bytes.NewReader(data), 256*1024), opts...)
+		},
+		"short reads": func() *fastcdc.Chunker { return mustChunker(t, iotest.HalfReader(bytes.NewReader(data)), opts...) },
+		"bytes": func() *fastcdc.Chunker {
+			c, err := fastcdc.NewChunkerFromBytes(data, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			return c
+		},
+	}
+
+	for name, input := range inputs {
+		// Skip at the start, within the buffer and past it, after a chunk
+		for _, skip := range []int64{0, 1000, 300_000, 900_000} {
+			c := input()
+
+			first, err := c.Next()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			n, err := c.Skip(skip)
+			if err != nil || n != skip {
+				t.Fatalf("%s: Skip(%d) = %d, %v", name, skip, n, err)
+			}
+
+			start := uint64(first.Length) + uint64(skip) //nolint:gosec // G115
+			if c.Offset() != start {
+				t.Errorf("%s: Offset() = %d after Skip(%d), want %d", name, c.Offset(), skip, start)
+			}
+
+			got := chunks(c)
+			want := chunks(mustChunker(t, bytes.NewReader(data[start:]), opts...))
+
+			if len(got) != len(want) {
+				t.Fatalf("%s: got %d chunks after Skip(%d), want %d", name, len(got), skip, len(want))
+			}
+
+			for i := range want {
+				want[i].Offset += start
+				if got[i].Offset != want[i].Offset || got[i].Length != want[i].Length || got[i].Hash != want[i].Hash ||
+					got[i].Last != want[i].Last {
+					t.Errorf("%s: chunk %d after Skip(%d) = %+v, want %+v", name, i, skip, got[i], want[i])
+				}
+			}
+		}
+
+		// Skipping past the end stops at EOF
+		c := input()
+
+		n, err := c.Skip(int64(len(data)) + 10)
+		if !errors.Is(err, io.EOF) || n != int64(len(data)) {
+			t.Errorf("%s: Skip past the end = %d, %v, want %d, io.EOF", name, n, err, len(data))
+		}
+
+		if _, err := c.Next(); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: Next after skipping everything = %v, want io.EOF", name, err)
+		}
+	}
+
+	c := mustChunker(t, bytes.NewReader(data))
+	if _, err := c.Skip(-1); !errors.Is(err, fastcdc.ErrNegativeSkip) {
+		t.Errorf("Skip(-1) = %v, want ErrNegativeSkip", err)
+	}
+}
+
+func TestWithWideFingerprint(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(512*1024, 1306)
+	edited := bytes.Clone(data)
+	edited[0]++
+
+	first := func(data []byte, opts ...fastcdc.Option) fastcdc.Chunk {
+		chunk, err := mustChunker(t, bytes.NewReader(data), opts...).Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return chunk
+	}
+
+	plain := first(data)
+	if plain.Hash128 != [2]uint64{} {
+		t.Errorf("Hash128 = %x without WithWideFingerprint, want zero", plain.Hash128)
+	}
+
+	a := first(data, fastcdc.WithWideFingerprint())
+	b := first(edited, fastcdc.WithWideFingerprint())
+
+	if a.Hash128[0] != a.Hash || a.Hash != plain.Hash || a.Length != plain.Length {
+		t.Errorf("low lane %x, Hash %x, want both equal to %x", a.Hash128[0], a.Hash, plain.Hash)
+	}
+
+	// Chunks that only differ before their last 64 bytes share the Gear fingerprint
+	if a.Length != b.Length || a.Hash != b.Hash {
+		t.Fatalf("edited chunk has length %d and hash %x, want %d and %x", b.Length, b.Hash, a.Length, a.Hash)
+	}
+
+	if a.Hash128 == b.Hash128 {
+		t.Errorf("Hash128 = %x for both chunks, want the high lane to differ", a.Hash128)
+	}
+
+	if again := first(data, fastcdc.WithWideFingerprint()); again.Hash128 != a.Hash128 {
+		t.Errorf("Hash128 = %x, then %x for the same chunk", a.Hash128, again.Hash128)
+	}
+
+	_, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithWideFingerprint(), fastcdc.WithoutHash())
+	if !errors.Is(err, fastcdc.ErrWideWithoutHash) {
+		t.Errorf("got %v, want ErrWideWithoutHash", err)
+	}
+}
+
+// flakyReader fails once, after its first failAt bytes, with errTestRead.
+type flakyReader struct {
+	r      io.Reader
+	failAt int
+	read   int
+	failed bool
+}
+
+func (f *flakyReader) Read(p []byte) (int, error) {
+	if !f.failed && f.read+len(p) > f.failAt {
+		p = p[:f.failAt-f.read]
+		f.failed = true
+
+		n, _ := io.ReadFull(f.r, p)
+		f.read += n
+
+		return n, errTestRead
+	}
+
+	n, err := f.r.Read(p)
+	f.read += n
+
+	return n, err
+}
+
+func TestReadError(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1307)
+	failAt := 700_000
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	inputs := map[string]func(io.Reader) io.Reader{
+		"reader": func(r io.Reader) io.Reader { return r },
+		"bufio":  func(r io.Reader) io.Reader { return bufio.NewReaderSize(r, 256*1024) },
+	}
+
+	want := mustChunker(t, bytes.NewReader(data), opts...)
+
+	for name, input := range inputs {
+		c := mustChunker(t, input(&flakyReader{r: bytes.NewReader(data), failAt: failAt}), opts...)
+
+		var end uint64
+
+		for {
+			chunk, err := c.Next()
+			if err != nil {
+				var readErr *fastcdc.ReadError
+				if !errors.As(err, &readErr) || !errors.Is(err, errTestRead) {
+					t.Fatalf("%s: got %v, want a ReadError wrapping the read error", name, err)
+				}
+
+				if readErr.Offset != uint64(failAt) {
+					t.Errorf("%s: ReadError.Offset = %d, want %d", name, readErr.Offset, failAt)
+				}
+
+				break
+			}
+
+			end = chunk.Offset + uint64(chunk.Length)
+		}
+
+		if end > uint64(failAt) {
+			t.Errorf("%s: chunks reached offset %d past the failed read", name, end)
+		}
+
+		// The reader recovers, so chunking resumes with the same chunks
+		want.Reset(bytes.NewReader(data))
+
+		for {
+			expected, err := want.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if expected.Offset < end {
+				continue
+			}
+
+			chunk, err := c.Next()
+			if err != nil {
+				t.Fatalf("%s: after the error: %v", name, err)
+			}
+
+			if chunk.Offset != expected.Offset || chunk.Length != expected.Length || chunk.Hash != expected.Hash {
+				t.Fatalf("%s: chunk after the error = %d+%d, want %d+%d", name, chunk.Offset, chunk.Length,
+					expected.Offset, expected.Length)
+			}
+		}
+
+		if _, err := c.Next(); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: got %v at the end, want io.EOF", name, err)
+		}
+	}
+
+	// End of stream is still reported as a plain io.EOF
+	c := mustChunker(t, bytes.NewReader(nil))
+	if _, err := c.Next(); err != io.EOF { //nolint:errorlint // io.EOF must not be wrapped
+		t.Errorf("got %v, want io.EOF", err)
+	}
+}
+
+func TestWithBuffer(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1308)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	var want []uint64
+
+	err := mustChunker(t, bytes.NewReader(data), opts...).Process(func(chunk fastcdc.Chunk) error {
+		want = append(want, chunk.Offset)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	buf := make([]byte, 100*1024)
+	inBuf := func(b []byte) bool {
+		// Slices of buf share the end of its capacity
+		return cap(b) > 0 && &b[:cap(b)][cap(b)-1] == &buf[len(buf)-1]
+	}
+
+	// offsets chunks the rest of the stream, checking that the data is in buf
+	offsets := func(c *fastcdc.Chunker) []uint64 {
+		var out []uint64
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			if !inBuf(chunk.Data) {
+				t.Fatalf("chunk at %d is not in the supplied buffer", chunk.Offset)
+			}
+
+			out = append(out, chunk.Offset)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return out
+	}
+
+	c := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithBuffer(buf))...)
+	if got := offsets(c); !slices.Equal(got, want) {
+		t.Errorf("got %d chunks with a supplied buffer, want %d", len(got), len(want))
+	}
+
+	// The buffer is kept across Reset and options that still fit in it
+	if err := c.Reconfigure(fastcdc.WithNormalization(2)); err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reset(bytes.NewReader(data))
+
+	if got := offsets(c); !slices.Equal(got, want) {
+		t.Errorf("got %d chunks after Reset, want %d", len(got), len(want))
+	}
+
+	// Switching to the supplied buffer mid-stream keeps the unconsumed bytes,
+	// which must fit
+	c = mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithBufferSize(70*1024))...)
+
+	first, err := c.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := c.Reconfigure(fastcdc.WithBuffer(buf)); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := append([]uint64{first.Offset}, offsets(c)...); !slices.Equal(got, want) {
+		t.Errorf("got %d chunks after switching buffers, want %d", len(got), len(want))
+	}
+
+	if err := c.Reconfigure(fastcdc.WithBuffer(make([]byte, 64*1024))); !errors.Is(err, fastcdc.ErrBufferTooSmall) {
+		t.Errorf("Reconfigure with a small buffer: got %v, want ErrBufferTooSmall", err)
+	}
+
+	_, err = fastcdc.NewChunker(bytes.NewReader(data), append(opts, fastcdc.WithBuffer(make([]byte, 64*1024)))...)
+	if !errors.Is(err, fastcdc.ErrBufferTooSmall) {
+		t.Errorf("buffer of maxSize: got %v, want ErrBufferTooSmall", err)
+	}
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithBuffer(buf)); !errors.Is(err, fastcdc.ErrSharedBuffer) {
+		t.Errorf("NewConfig: got %v, want ErrSharedBuffer", err)
+	}
+
+	if _, err := fastcdc.NewChunkerPool(fastcdc.WithBuffer(buf)); !errors.Is(err, fastcdc.ErrSharedBuffer) {
+		t.Errorf("NewChunkerPool: got %v, want ErrSharedBuffer", err)
+	}
+}
+
+// TestWithoutHash tests that WithoutHash keeps boundaries and zeroes Chunk.Hash.
+func TestWithoutHash(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(512*1024, 1)
+	want := mustChunker(t, bytes.NewReader(data))
+	got := mustChunker(t, bytes.NewReader(data), fastcdc.WithoutHash())
+
+	for {
+		w, werr := want.Next()
+		g, gerr := got.Next()
+
+		if !errors.Is(gerr, werr) {
+			t.Fatalf("got error %v, want %v", gerr, werr)
+		}
+
+		if werr != nil {
+			break
+		}
+
+		if g.Offset != w.Offset || g.Length != w.Length {
+			t.Fatalf("got chunk at %d of %d bytes, want %d of %d bytes", g.Offset, g.Length, w.Offset, w.Length)
+		}
+
+		if g.Hash != 0 {
+			t.Errorf("chunk at %d has hash %#x, want 0", g.Offset, g.Hash)
+		}
+	}
+
+	_, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithoutHash(), fastcdc.WithChunkDigest(sha256.New))
+	if !errors.Is(err, fastcdc.ErrDigestWithoutHash) {
+		t.Errorf("expected ErrDigestWithoutHash, got %v", err)
+	}
+}
+
+// TestForcedCuts tests counting chunks cut at maxSize.
+func TestForcedCuts(t *testing.T) {
+	t.Parallel()
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(1024),
+		fastcdc.WithTargetSize(4096),
+		fastcdc.WithMaxSize(8192),
+	}
+
+	// Zeros never match the mask, so every full chunk is forced
+	zeros := make([]byte, 100*8192+100)
+	c := mustChunker(t, bytes.NewReader(zeros), opts...)
+
+	if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := c.ForcedCuts(); got != 100 {
+		t.Errorf("zeros: ForcedCuts() = %d, want 100", got)
+	}
+
+	// On random data the count agrees with the diagnostics
+	data := randBytes(4*1024*1024, 1220)
+
+	c.Reset(bytes.NewReader(data))
+
+	if got := c.ForcedCuts(); got != 0 {
+		t.Errorf("ForcedCuts() = %d after Reset, want 0", got)
+	}
+
+	if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, want := core.MaskMatchStats(data); c.ForcedCuts() != uint64(want) || want == 0 { //nolint:gosec // G115
+		t.Errorf("random data: ForcedCuts() = %d, want %d", c.ForcedCuts(), want)
+	}
+}
+
+func TestFindBoundaryEx(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1302)
+
+	for _, rolling := range []fastcdc.RollingHash{fastcdc.HashGear, fastcdc.HashRabin} {
+		opts := []fastcdc.Option{
+			fastcdc.WithMinSize(1024),
+			fastcdc.WithTargetSize(4096),
+			fastcdc.WithMaxSize(8192),
+			fastcdc.WithRollingHash(rolling),
+		}
+
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reference, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		reasons := map[fastcdc.BoundaryReason]int{}
+
+		// Feed the data in pieces so that chunks span several calls
+		var chunkLen int
+
+		for offset := 0; offset < len(data); {
+			piece := data[offset:min(offset+1000, len(data))]
+
+			result, found := core.FindBoundaryEx(piece)
+			if !found {
+				chunkLen += len(piece)
+				offset += len(piece)
+
+				continue
+			}
+
+			chunkLen += result.Offset
+			offset += result.Offset
+
+			wantLen, wantHash, _ := reference.FindBoundary(data[offset-chunkLen:])
+			if chunkLen != wantLen || result.Hash != wantHash {
+				t.Fatalf("rolling %d: chunk of %d bytes with hash %x, FindBoundary gives %d with %x",
+					rolling, chunkLen, result.Hash, wantLen, wantHash)
+			}
+
+			var want fastcdc.BoundaryReason
+
+			switch {
+			case chunkLen <= int(core.NormSize()):
+				want = fastcdc.NaturalSmall
+			case chunkLen < int(core.MaxSize()):
+				want = fastcdc.NaturalLarge
+			}
+
+			if want != 0 && result.Reason != want {
+				t.Errorf("rolling %d: chunk of %d bytes has reason %d, want %d", rolling, chunkLen, result.Reason, want)
+			}
+
+			reasons[result.Reason]++
+			chunkLen = 0
+
+			core.Reset()
+			reference.Reset()
+		}
+
+		_, _, forced := core.MaskMatchStats(data)
+		if reasons[fastcdc.MaxLimit] != forced || forced == 0 {
+			t.Errorf("rolling %d: %d MaxLimit cuts, want %d", rolling, reasons[fastcdc.MaxLimit], forced)
+		}
+
+		if reasons[fastcdc.NaturalSmall] == 0 || reasons[fastcdc.NaturalLarge] == 0 {
+			t.Errorf("rolling %d: expected both natural reasons, got %v", rolling, reasons)
+		}
+	}
+
+	core, err := fastcdc.NewChunkerCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if result, found := core.FindBoundaryEx(data[:100]); found || result != (fastcdc.BoundaryResult{}) {
+		t.Errorf("short data: got %+v, %v, want a zero result", result, found)
+	}
+}
+
+func TestWithAdaptiveTarget(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(8*1024*1024, 1303)
+
+	// Masks with far more bits than targetSize calls for make chunks run
+	// towards maxSize unless they adapt
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(256),
+		fastcdc.WithTargetSize(1024),
+		fastcdc.WithMaxSize(16 * 1024),
+		fastcdc.WithMasks(1<<13-1, 1<<14-1),
+	}
+
+	// meanAfter returns the mean size of the chunks starting past half of data
+	meanAfter := func(c *fastcdc.Chunker) float64 {
+		var count, total int
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			if chunk.Offset >= uint64(len(data)/2) {
+				count++
+				total += int(chunk.Length)
+			}
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return float64(total) / float64(count)
+	}
+
+	static := meanAfter(mustChunker(t, bytes.NewReader(data), opts...))
+
+	c := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithAdaptiveTarget())...)
+	adaptive := meanAfter(c)
+
+	t.Logf("Mean chunk size: static %.0f, adaptive %.0f", static, adaptive)
+
+	if static < 4*1024 {
+		t.Fatalf("static mean %.0f, expected the masks to overshoot targetSize", static)
+	}
+
+	if adaptive < 1024*0.6 || adaptive > 1024*1.5 {
+		t.Errorf("adaptive mean %.0f, want near targetSize 1024", adaptive)
+	}
+
+	// Reset starts over from the configured masks
+	c.Reset(bytes.NewReader(data))
+
+	if again := meanAfter(c); again != adaptive {
+		t.Errorf("after Reset, adaptive mean %.0f, want %.0f", again, adaptive)
+	}
+}
+
+// TestWithTailPolicy tests the handling of a final chunk shorter than minSize.
+func TestWithTailPolicy(t *testing.T) {
+	t.Parallel()
+
+	sizes := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024)}
+
+	full := randBytes(1024*1024, 1294)
+
+	boundaries, err := fastcdc.GoldenBoundaries(full, sizes...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Chunks before the tail are unaffected by cutting the input short
+	k := len(boundaries) / 2
+	data := full[:boundaries[k-1]+100]
+
+	inputs := map[string]func([]byte) io.Reader{
+		"reader":      func(b []byte) io.Reader { return bytes.NewReader(b) },
+		"large bufio": func(b []byte) io.Reader { return bufio.NewReaderSize(bytes.NewReader(b), 512*1024) },
+		"short reads": func(b []byte) io.Reader { return iotest.OneByteReader(bytes.NewReader(b)) },
+		// Holds a maximum-size chunk but not the tail after it
+		"small bufio": func(b []byte) io.Reader { return bufio.NewReaderSize(bytes.NewReader(b), fastcdc.DefaultMaxSize+16) },
+	}
+
+	for name, input := range inputs {
+		lengths := func(data []byte, policy fastcdc.TailPolicy) ([]int, error) {
+			c := mustChunker(t, input(data), append(slices.Clone(sizes), fastcdc.WithTailPolicy(policy))...)
+
+			var ends []int
+
+			err := c.Process(func(chunk fastcdc.Chunk) error {
+				ends = append(ends, int(chunk.Offset)+int(chunk.Length)) //nolint:gosec // G115
+
+				return nil
+			})
+
+			return ends, err
+		}
+
+		got, err := lengths(data, fastcdc.TailEmit)
+		if err != nil || !slices.Equal(got, append(slices.Clone(boundaries[:k]), len(data))) {
+			t.Errorf("%s: TailEmit: got %v (%v), want the tail as its own chunk", name, got, err)
+		}
+
+		got, err = lengths(data, fastcdc.TailMerge)
+
+		want := append(slices.Clone(boundaries[:k-1]), len(data))
+		if err != nil || !slices.Equal(got, want) {
+			t.Errorf("%s: TailMerge: got %v (%v), want %v", name, got, err, want)
+		}
+
+		got, err = lengths(data, fastcdc.TailError)
+		if !errors.Is(err, fastcdc.ErrShortTail) || !slices.Equal(got, boundaries[:k]) {
+			t.Errorf("%s: TailError: got %v (%v), want ErrShortTail after %d chunks", name, got, err, k)
+		}
+
+		// An input shorter than minSize has nothing to merge into
+		got, err = lengths(data[:100], fastcdc.TailMerge)
+		if err != nil || !slices.Equal(got, []int{100}) {
+			t.Errorf("%s: short input with TailMerge: got %v (%v), want [100]", name, got, err)
+		}
+
+		if _, err := lengths(data[:100], fastcdc.TailError); !errors.Is(err, fastcdc.ErrShortTail) {
+			t.Errorf("%s: short input with TailError: expected ErrShortTail, got %v", name, err)
+		}
+	}
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithTailPolicy(fastcdc.TailError + 1)); !errors.Is(err, fastcdc.ErrInvalidTailPolicy) {
+		t.Errorf("expected ErrInvalidTailPolicy, got %v", err)
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(10*1024*1024+123, 1315)
+
+	var reports []uint64
+
+	c := mustChunker(t, bytes.NewReader(data), fastcdc.WithProgress(func(n uint64) {
+		reports = append(reports, n)
+	}))
+
+	var chunks int
+
+	if err := c.Process(func(fastcdc.Chunk) error { chunks++; return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(reports) < 10 || len(reports) > 12 || len(reports) >= chunks {
+		t.Fatalf("got %d reports for %d chunks, want one per MiB", len(reports), chunks)
+	}
+
+	for i := 1; i < len(reports); i++ {
+		if reports[i]-reports[i-1] < 1024*1024 && i != len(reports)-1 {
+			t.Errorf("reports %d and %d only %d bytes apart", i-1, i, reports[i]-reports[i-1])
+		}
+	}
+
+	if last := reports[len(reports)-1]; last != uint64(len(data)) {
+		t.Errorf("last report %d, want the input length %d", last, len(data))
+	}
+
+	// Reset starts counting over
+	reports = nil
+
+	c.Reset(bytes.NewReader(data[:100]))
+
+	if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(reports, []uint64{100}) {
+		t.Errorf("after Reset, got reports %v, want [100]", reports)
+	}
+}
+
+func TestFindBoundaryFinal(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024+1317, 1317)
+
+	for _, rolling := range []fastcdc.RollingHash{fastcdc.HashGear, fastcdc.HashRabin} {
+		want, err := fastcdc.GoldenBoundaries(data, fastcdc.WithRollingHash(rolling))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		core, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(rolling))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var got []int
+
+		for offset := 0; offset < len(data); {
+			boundary, _ := core.FindBoundaryFinal(data[offset:])
+			if core.Position() != 0 {
+				t.Fatalf("rolling %d: position %d after the boundary at %d", rolling, core.Position(), offset+boundary)
+			}
+
+			offset += boundary
+			got = append(got, offset)
+
+			core.Reset()
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("rolling %d: got %d boundaries, want %d", rolling, len(got), len(want))
+		}
+	}
+}
+
+func TestWithUnroll(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024, 1318)
+
+	for _, maxSize := range []uint32{256, 4096, 256 * 1024} {
+		sizes := []fastcdc.Option{
+			fastcdc.WithMinSize(maxSize / 4), fastcdc.WithTargetSize(maxSize / 2), fastcdc.WithMaxSize(maxSize),
+		}
+
+		want, err := fastcdc.GoldenBoundaries(data, sizes...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		got, err := fastcdc.GoldenBoundaries(data, append(sizes, fastcdc.WithUnroll(false))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !slices.Equal(got, want) {
+			t.Errorf("maxSize %d: plain loop found %d boundaries, unrolled %d", maxSize, len(got), len(want))
+		}
+
+		// Chunks spanning several calls of 100 bytes
+		core, err := fastcdc.NewChunkerCore(append(sizes, fastcdc.WithUnroll(false))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			pieces []int
+			offset int
+		)
+
+		for block := range slices.Chunk(data, 100) {
+			for len(block) > 0 {
+				boundary, _, found := core.FindBoundary(block)
+				offset += boundary
+				block = block[boundary:]
+
+				if found {
+					pieces = append(pieces, offset)
+					core.Reset()
+				}
+			}
+		}
+
+		if offset > 0 && (len(pieces) == 0 || pieces[len(pieces)-1] != offset) {
+			pieces = append(pieces, offset)
+		}
+
+		if !slices.Equal(pieces, want) {
+			t.Errorf("maxSize %d: plain loop over pieces found %d boundaries, want %d", maxSize, len(pieces), len(want))
+		}
+	}
+}
+
+func TestWithPreferredDelimiter(t *testing.T) {
+	t.Parallel()
+
+	// Records of 20 to 200 bytes ending in a newline
+	var data []byte
+
+	random := randBytes(1024*1024, 1319)
+	for i := 0; len(data) < len(random)-256; i++ {
+		line := random[len(data) : len(data)+20+int(random[i])%181]
+		for j := range line[:len(line)-1] {
+			line[j] = 'a' + line[j]%26
+		}
+
+		line[len(line)-1] = '\n'
+		data = append(data, line...)
+	}
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(1024), fastcdc.WithTargetSize(4 * 1024), fastcdc.WithMaxSize(16 * 1024),
+		fastcdc.WithPreferredDelimiter('\n', 256),
+	}
+
+	// chunkEnds returns the end offsets of the chunks of data read from r
+	chunkEnds := func(r io.Reader) []int {
+		var ends []int
+
+		err := mustChunker(t, r, opts...).Process(func(chunk fastcdc.Chunk) error {
+			if !chunk.Last && (chunk.Length < 1024 || chunk.Length > 16*1024) {
+				t.Errorf("chunk at %d has length %d outside [minSize, maxSize]", chunk.Offset, chunk.Length)
+			}
+
+			if chunk.Data[len(chunk.Data)-1] != '\n' {
+				t.Errorf("chunk at %d of length %d does not end a record", chunk.Offset, chunk.Length)
+			}
+
+			ends = append(ends, int(chunk.Offset)+int(chunk.Length))
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ends
+	}
+
+	ends := chunkEnds(bytes.NewReader(data))
+	if ends[len(ends)-1] != len(data) {
+		t.Fatalf("chunks end at %d, want %d", ends[len(ends)-1], len(data))
+	}
+
+	if buffered := chunkEnds(bufio.NewReaderSize(bytes.NewReader(data), 64*1024)); !slices.Equal(buffered, ends) {
+		t.Error("chunks differ when reading from a bufio.Reader")
+	}
+
+	// Boundaries only move within the window
+	natural, err := fastcdc.GoldenBoundaries(data, opts[:3]...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if moved := ends[0] - natural[0]; moved < -256 || moved > 256 {
+		t.Errorf("first boundary moved by %d bytes, window is 256", moved)
+	}
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithPreferredDelimiter('\n', 0)); !errors.Is(err, fastcdc.ErrInvalidDelimiterWindow) {
+		t.Errorf("window 0: got %v, want ErrInvalidDelimiterWindow", err)
+	}
+}
+
+// limitWriter accepts n bytes, then writes part of the next buffer and fails
+// with err, or returns a short write if err is nil.
+type limitWriter struct {
+	n   int
+	err error
+}
+
+func (w *limitWriter) Write(p []byte) (int, error) {
+	if len(p) <= w.n {
+		w.n -= len(p)
+
+		return len(p), nil
+	}
+
+	n := w.n
+	w.n = 0
+
+	return n, w.err
+}
+
+func TestWithTee(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(3*1024*1024+1321, 1321)
+	want := sha256.Sum256(data)
+
+	readers := map[string]func() io.Reader{
+		"buffer": func() io.Reader { return iotest.HalfReader(bytes.NewReader(data)) },
+		"bufio":  func() io.Reader { return bufio.NewReaderSize(bytes.NewReader(data), 512*1024) },
+	}
+
+	for name, reader := range readers {
+		whole := sha256.New()
+
+		c := mustChunker(t, reader(), fastcdc.WithTee(whole), fastcdc.WithBufferSize(fastcdc.DefaultMaxSize+1))
+		if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(whole.Sum(nil), want[:]) {
+			t.Errorf("%s: tee digest differs from the input", name)
+		}
+	}
+
+	// Failures stop chunking until Reset
+	errTee := errors.New("tee failed")
+
+	for _, tt := range []struct {
+		err  error
+		want error
+	}{
+		{errTee, errTee},
+		{nil, io.ErrShortWrite},
+	} {
+		w := &limitWriter{n: 100 * 1024, err: tt.err}
+		c := mustChunker(t, bytes.NewReader(data), fastcdc.WithTee(w))
+
+		err := c.Process(func(fastcdc.Chunk) error { return nil })
+		if !errors.Is(err, tt.want) {
+			t.Fatalf("got %v, want %v", err, tt.want)
+		}
+
+		if _, err2 := c.Next(); err2 != err { //nolint:errorlint // the same error is returned
+			t.Errorf("Next after the failure returned %v, want %v", err2, err)
+		}
+
+		w.n = len(data)
+		c.Reset(bytes.NewReader(data))
+
+		if err := c.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+			t.Errorf("after Reset: %v", err)
+		}
+	}
+}
+
+func TestChunkerFork(t *testing.T) {
+	t.Parallel()
+
+	outer := randBytes(1024*1024, 1325)
+	inner := randBytes(300*1024, 13250)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithCopyData()}
+
+	// ends returns the end offsets of the chunks read from c
+	ends := func(c *fastcdc.Chunker, each func()) []int {
+		var ends []int
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			ends = append(ends, int(chunk.Offset)+int(chunk.Length))
+			each()
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return ends
+	}
+
+	wantOuter := ends(mustChunker(t, bytes.NewReader(outer), opts...), func() {})
+	wantInner := ends(mustChunker(t, bytes.NewReader(inner), opts...), func() {})
+
+	// Chunk the inner stream in the middle of every outer chunk
+	parent := mustChunker(t, bytes.NewReader(outer), opts...)
+	r := bytes.NewReader(nil)
+
+	gotOuter := ends(parent, func() {
+		child := parent.Fork()
+		if child != parent.Fork() {
+			t.Fatal("Fork returned a different child")
+		}
+
+		r.Reset(inner)
+		child.Reset(r)
+
+		if got := ends(child, func() {}); !slices.Equal(got, wantInner) {
+			t.Fatalf("forked chunker found %d boundaries, want %d", len(got), len(wantInner))
+		}
+	})
+
+	if !slices.Equal(gotOuter, wantOuter) {
+		t.Errorf("parent found %d boundaries with forks, want %d", len(gotOuter), len(wantOuter))
+	}
+
+}
+
+// TestChunkerForkAllocs tests that chunking members with a fork does not allocate
+// after the first one.
+//
+//nolint:paralleltest // AllocsPerRun cannot be used in parallel tests
+func TestChunkerForkAllocs(t *testing.T) {
+	inner := randBytes(300*1024, 13250)
+	parent := mustChunker(t, bytes.NewReader(randBytes(1024, 1325)))
+	r := bytes.NewReader(nil)
+
+	drain := func() {
+		child := parent.Fork()
+		r.Reset(inner)
+		child.Reset(r)
+
+		if err := child.Process(func(fastcdc.Chunk) error { return nil }); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	drain()
+
+	if allocs := testing.AllocsPerRun(10, drain); allocs != 0 {
+		t.Errorf("forking and chunking a member allocated %.0f times", allocs)
+	}
+}
+
+func TestWithFixedSize(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(10*1000+500, 1326)
+
+	c := mustChunker(t, iotest.HalfReader(bytes.NewReader(data)), fastcdc.WithFixedSize(1000),
+		fastcdc.WithMinSize(1)) // Replaced by the fixed size
+
+	var lengths []uint32
+
+	err := c.Process(func(chunk fastcdc.Chunk) error {
+		if chunk.Offset != uint64(len(lengths))*1000 || chunk.Hash != 0 {
+			t.Errorf("chunk %d at offset %d with hash %x", len(lengths), chunk.Offset, chunk.Hash)
+		}
+
+		lengths = append(lengths, chunk.Length)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []uint32{1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 1000, 500}
+	if !slices.Equal(lengths, want) {
+		t.Errorf("got chunk lengths %v, want %v", lengths, want)
+	}
+
+	// The core cuts at the fixed size across calls
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithFixedSize(1000))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if boundary, _, found := core.FindBoundary(data[:600]); found || boundary != 600 {
+		t.Errorf("FindBoundary of 600 bytes = %d, %v", boundary, found)
+	}
+
+	if result, found := core.FindBoundaryEx(data[600:]); !found || result.Offset != 400 || result.Reason != fastcdc.MaxLimit {
+		t.Errorf("FindBoundaryEx after 600 bytes = %+v, %v, want a MaxLimit cut at 400", result, found)
+	}
+
+	for _, tt := range []struct {
+		opts []fastcdc.Option
+		want error
+	}{
+		{[]fastcdc.Option{fastcdc.WithFixedSize(0)}, fastcdc.ErrInvalidFixedSize},
+		{[]fastcdc.Option{fastcdc.WithFixedSize(1000), fastcdc.WithNormalization(1)}, fastcdc.ErrFixedSizeConflict},
+		{[]fastcdc.Option{fastcdc.WithFixedSize(1000), fastcdc.WithMasks(1, 3)}, fastcdc.ErrFixedSizeConflict},
+		{[]fastcdc.Option{fastcdc.WithFixedSize(1000), fastcdc.WithAdaptiveTarget()}, fastcdc.ErrFixedSizeConflict},
+	} {
+		if _, err := fastcdc.NewConfig(tt.opts...); !errors.Is(err, tt.want) {
+			t.Errorf("got %v, want %v", err, tt.want)
+		}
+	}
+}
+
+// flakySeeker fails every read after failEvery successful ones, consuming up to
+// 1000 bytes it does not return, like a connection dropped mid-transfer.
+type flakySeeker struct {
+	*bytes.Reader
+	failEvery int
+	reads     int
+}
+
+func (f *flakySeeker) Read(p []byte) (int, error) {
+	f.reads++
+	if f.reads%(f.failEvery+1) == 0 {
+		_, _ = f.Reader.Read(make([]byte, min(len(p), 1000)))
+
+		return 0, errTestRead
+	}
+
+	return f.Reader.Read(p)
+}
+
+func TestWithRetry(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024, 1328)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithBufferSize(64 * 1024)}
+
+	// digests chunks r and returns the digests of the chunks
+	digests := func(r io.Reader, opts ...fastcdc.Option) ([][32]byte, error) {
+		var sums [][32]byte
+
+		err := mustChunker(t, r, opts...).Process(func(chunk fastcdc.Chunk) error {
+			sums = append(sums, sha256.Sum256(chunk.Data))
+
+			return nil
+		})
+
+		return sums, err
+	}
+
+	want, err := digests(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The data follows a prefix of 10 bytes, so rewinding must be relative
+	prefixed := append(make([]byte, 10), data...)
+
+	newReader := func() *flakySeeker {
+		r := &flakySeeker{Reader: bytes.NewReader(prefixed), failEvery: 3}
+		_, _ = r.Seek(10, io.SeekStart)
+
+		return r
+	}
+
+	got, err := digests(newReader(), append(opts, fastcdc.WithRetry(1))...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(got), len(want))
+	}
+
+	if !slices.Equal(got, want) {
+		t.Fatal("chunks differ after retries")
+	}
+
+	// Without retries, or without a seekable reader, the error is returned
+	if _, err := digests(newReader(), opts...); !errors.Is(err, errTestRead) {
+		t.Errorf("without WithRetry: got %v, want the read error", err)
+	}
+
+	notSeekable := struct{ io.Reader }{newReader()}
+	if _, err := digests(notSeekable, append(opts, fastcdc.WithRetry(1))...); !errors.Is(err, errTestRead) {
+		t.Errorf("without io.Seeker: got %v, want the read error", err)
+	}
+
+	// Every read failing exhausts the retries
+	failing := &flakySeeker{Reader: bytes.NewReader(data), failEvery: 0}
+
+	var readErr *fastcdc.ReadError
+	if _, err := digests(failing, append(opts, fastcdc.WithRetry(2))...); !errors.As(err, &readErr) {
+		t.Errorf("always failing: got %v, want a *ReadError", err)
+	} else if failing.reads != 3 {
+		t.Errorf("always failing: read %d times, want 3", failing.reads)
+	}
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithRetry(-1)); !errors.Is(err, fastcdc.ErrInvalidRetryCount) {
+		t.Errorf("WithRetry(-1): got %v, want ErrInvalidRetryCount", err)
+	}
+}
+
+// TestWithPartialChunks tests that chunks longer than a small buffer are
+// returned in parts that add up to the chunks of a buffer holding them whole.
+func TestWithPartialChunks(t *testing.T) {
+	t.Parallel()
+
+	type whole struct {
+		offset uint64
+		length uint32
+		hash   uint64
+		digest string
+		last   bool
+	}
+
+	// collect chunks r, joining parts into whole chunks, and counts the parts
+	collect := func(r io.Reader, opts ...fastcdc.Option) ([]whole, int, *fastcdc.Chunker) {
+		c := mustChunker(t, r, append(opts, fastcdc.WithChunkDigest(sha256.New), fastcdc.WithStats(true))...)
+
+		var (
+			chunks []whole
+			parts  int
+			part   *whole
+			sum    = sha256.New()
+		)
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			if part == nil {
+				part = &whole{offset: chunk.Offset}
+				sum.Reset()
+			}
+
+			if chunk.Offset != part.offset+uint64(part.length) {
+				t.Fatalf("part at %d does not follow the previous one", chunk.Offset)
+			}
+
+			if chunk.Length != uint32(len(chunk.Data)) || chunk.Length == 0 { //nolint:gosec // G115
+				t.Fatalf("part at %d has length %d and %d bytes", chunk.Offset, chunk.Length, len(chunk.Data))
+			}
+
+			sum.Write(chunk.Data)
+			part.length += chunk.Length
+
+			if chunk.Partial {
+				parts++
+
+				if chunk.Hash != 0 || chunk.Digest != nil || chunk.Last {
+					t.Fatalf("part at %d has the fields of a final part", chunk.Offset)
+				}
+
+				return nil
+			}
+
+			if string(chunk.Digest) != string(sum.Sum(nil)) {
+				t.Fatalf("chunk at %d: digest of the last part is not the digest of the parts", part.offset)
+			}
+
+			part.hash, part.digest, part.last = chunk.Hash, string(chunk.Digest), chunk.Last
+			chunks = append(chunks, *part)
+			part = nil
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if part != nil {
+			t.Fatal("the stream ended with a partial chunk")
+		}
+
+		return chunks, parts, c
+	}
+
+	// Chunks of about 1 MiB, up to 4 MiB
+	sizes := []fastcdc.Option{
+		fastcdc.WithMinSize(256 * 1024),
+		fastcdc.WithTargetSize(1024 * 1024),
+		fastcdc.WithMaxSize(4 * 1024 * 1024),
+	}
+
+	data := randBytes(16*1024*1024, 1334)
+	want, _, wantChunker := collect(bytes.NewReader(data), sizes...)
+
+	for _, bufSize := range []int{300 * 1024, 1024 * 1024, 8 * 1024 * 1024} {
+		opts := append(slices.Clone(sizes), fastcdc.WithPartialChunks(), fastcdc.WithBufferSize(bufSize))
+
+		for name, r := range map[string]io.Reader{
+			"reader": bytes.NewReader(data),
+			"half":   iotest.HalfReader(bytes.NewReader(data)),
+			"bufio":  bufio.NewReaderSize(bytes.NewReader(data), 1024*1024),
+		} {
+			got, parts, c := collect(r, opts...)
+			if !slices.Equal(got, want) {
+				t.Errorf("buffer %d, %s: chunks differ from whole ones", bufSize, name)
+			}
+
+			if (parts == 0) != (bufSize > 4*1024*1024) {
+				t.Errorf("buffer %d, %s: %d partial chunks", bufSize, name, parts)
+			}
+
+			if c.Stats() != wantChunker.Stats() || c.ForcedCuts() != wantChunker.ForcedCuts() {
+				t.Errorf("buffer %d, %s: stats %+v, forced %d, want %+v, %d", bufSize, name, c.Stats(), c.ForcedCuts(),
+					wantChunker.Stats(), wantChunker.ForcedCuts())
+			}
+
+			if !strings.Contains(c.String(), fmt.Sprintf("size=%d ", bufSize)) {
+				t.Errorf("buffer %d, %s: buffer resized: %s", bufSize, name, c)
+			}
+		}
+	}
+
+	// The smallest buffers, with the input ending after a full buffer
+	small := []fastcdc.Option{fastcdc.WithMinSize(64), fastcdc.WithTargetSize(256), fastcdc.WithMaxSize(1024)}
+
+	for _, bufSize := range []int{2, 3, 100} {
+		data := randBytes(100*bufSize, 1334)
+		want, _, _ := collect(bytes.NewReader(data), small...)
+
+		got, _, _ := collect(bytes.NewReader(data), append(slices.Clone(small), fastcdc.WithPartialChunks(),
+			fastcdc.WithBuffer(make([]byte, bufSize)))...)
+		if !slices.Equal(got, want) {
+			t.Errorf("buffer %d: chunks differ from whole ones", bufSize)
+		}
+	}
+
+	for _, tt := range []struct {
+		opts []fastcdc.Option
+		want error
+	}{
+		{[]fastcdc.Option{fastcdc.WithPartialChunks(), fastcdc.WithWideFingerprint()}, fastcdc.ErrPartialChunksConflict},
+		{[]fastcdc.Option{fastcdc.WithPartialChunks(), fastcdc.WithTailPolicy(fastcdc.TailMerge)}, fastcdc.ErrPartialChunksConflict},
+		{[]fastcdc.Option{fastcdc.WithPartialChunks(), fastcdc.WithBuffer(make([]byte, 1))}, fastcdc.ErrBufferTooSmall},
+	} {
+		if _, err := fastcdc.NewChunker(bytes.NewReader(nil), tt.opts...); !errors.Is(err, tt.want) {
+			t.Errorf("got %v, want %v", err, tt.want)
+		}
+	}
+}
+
+// TestWithChunkID tests that Chunk.ID is the SHA-256 of the namespace followed
+// by the chunk data, also for chunks returned in parts.
+func TestWithChunkID(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1337)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	// ids chunks data and checks every ID against its definition
+	ids := func(namespace []byte, opts ...fastcdc.Option) [][]byte {
+		c := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithChunkID(namespace))...)
+		namespace = slices.Clone(namespace)
+
+		var (
+			got   [][]byte
+			whole []byte
+		)
+
+		err := c.Process(func(chunk fastcdc.Chunk) error {
+			whole = append(whole, chunk.Data...)
+			if chunk.Partial {
+				if chunk.ID != nil {
+					t.Fatalf("part at %d has an ID", chunk.Offset)
+				}
+
+				return nil
+			}
+
+			if want := sha256.Sum256(append(slices.Clone(namespace), whole...)); !bytes.Equal(chunk.ID, want[:]) {
+				t.Fatalf("chunk at %d: ID %x, want %x", chunk.Offset, chunk.ID, want)
+			}
+
+			got = append(got, chunk.ID)
+			whole = whole[:0]
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return got
+	}
+
+	tenantA := ids([]byte("tenant-a"), opts...)
+	if tenantB := ids([]byte("tenant-b"), opts...); slices.EqualFunc(tenantA, tenantB, bytes.Equal) {
+		t.Error("different namespaces produced the same IDs")
+	}
+
+	parts := ids([]byte("tenant-a"), append(opts, fastcdc.WithPartialChunks(), fastcdc.WithBufferSize(10*1024))...)
+	if !slices.EqualFunc(parts, tenantA, bytes.Equal) {
+		t.Error("chunks returned in parts have different IDs")
+	}
+
+	// The namespace is copied
+	namespace := []byte("tenant-a")
+	c := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithChunkID(namespace))...)
+	namespace[0] = 'T'
+
+	if chunk, err := c.Next(); err != nil || !bytes.Equal(chunk.ID, tenantA[0]) {
+		t.Errorf("changing the namespace after NewChunker changed the ID: %v", err)
+	}
+
+	// With an empty namespace, the ID is the SHA-256 digest
+	c = mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithChunkID(nil), fastcdc.WithChunkDigest(sha256.New))...)
+	if chunk, err := c.Next(); err != nil || !bytes.Equal(chunk.ID, chunk.Digest) {
+		t.Errorf("empty namespace: ID %x, digest %x, %v", chunk.ID, chunk.Digest, err)
+	}
+
+	if chunk, err := mustChunker(t, bytes.NewReader(data), opts...).Next(); err != nil || chunk.ID != nil {
+		t.Errorf("ID without WithChunkID: %x, %v", chunk.ID, err)
+	}
+
+	if _, err := fastcdc.NewConfig(fastcdc.WithChunkID(nil), fastcdc.WithoutHash()); !errors.Is(err, fastcdc.ErrChunkIDWithoutHash) {
+		t.Errorf("with WithoutHash: got %v, want ErrChunkIDWithoutHash", err)
+	}
+}
+
+// TestAtEOF tests that AtEOF reports the end of the input as soon as the final
+// chunk is returned, like Chunk.Last.
+func TestAtEOF(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024+1000, 1338)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	for name, r := range map[string]io.Reader{
+		"reader":      bytes.NewReader(data),
+		"bufio":       bufio.NewReaderSize(bytes.NewReader(data), 256*1024),
+		"one byte":    iotest.OneByteReader(bytes.NewReader(data)),
+		"empty":       bytes.NewReader(nil),
+		"empty bufio": bufio.NewReaderSize(bytes.NewReader(nil), 256*1024),
+	} {
+		c := mustChunker(t, r, opts...)
+
+		for {
+			chunk, err := c.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if c.AtEOF() != chunk.Last {
+				t.Fatalf("%s: chunk at %d: AtEOF %v, Last %v", name, chunk.Offset, c.AtEOF(), chunk.Last)
+			}
+		}
+
+		if !c.AtEOF() {
+			t.Errorf("%s: AtEOF false after io.EOF", name)
+		}
+
+		c.Reset(bytes.NewReader(data))
+
+		if c.AtEOF() {
+			t.Errorf("%s: AtEOF true after Reset", name)
+		}
+	}
+
+	// Skipping past the end
+	c := mustChunker(t, bufio.NewReaderSize(bytes.NewReader(data), 256*1024), opts...)
+	if _, err := c.Skip(int64(len(data)) + 1); !errors.Is(err, io.EOF) || !c.AtEOF() {
+		t.Errorf("Skip past the end: %v, AtEOF %v", err, c.AtEOF())
+	}
+
+	// In-memory input has nothing left once its last chunk is returned
+	c, err := fastcdc.NewChunkerFromBytes(data[:1000], opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.AtEOF() {
+		t.Error("NewChunkerFromBytes: AtEOF before the only chunk")
+	}
+
+	if _, err := c.Next(); err != nil || !c.AtEOF() {
+		t.Errorf("NewChunkerFromBytes: %v, AtEOF %v after the only chunk", err, c.AtEOF())
+	}
+}
@@ -1,5 +1,11 @@
 package fastcdc
 
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
 // ChunkerCore implements zero-allocation content-defined chunking using the Gear hash algorithm.
 // It provides a low-level FindBoundary API for performance-critical code where managing buffers
 // manually is acceptable.
@@ -11,30 +17,46 @@ type ChunkerCore struct {
 	fingerprint uint64      // Current rolling hash value
 
 	// Config fields (read-only after initialization)
-	minSize   uint32 // Minimum chunk size
-	normSize  uint32 // Normalization boundary (minSize + normalized region)
-	maxSize   uint32 // Maximum chunk size
-	maskS     uint64 // Small mask for [minSize, normSize) region
-	maskL     uint64 // Large mask for [normSize, maxSize) region
-	bits      uint8  // Number of bits in target size
-	normLevel uint8  // Normalization level (0-8)
+	minSize    uint32      // Minimum chunk size
+	targetSize uint32      // Target chunk size
+	normSize   uint32      // Normalization boundary (minSize + normalized region)
+	maxSize    uint32      // Maximum chunk size
+	maskS      uint64      // Small mask for [minSize, normSize) region
+	maskL      uint64      // Large mask for [normSize, maxSize) region
+	matchS     uint64      // Value fp & maskS must equal at a boundary
+	matchL     uint64      // Value fp & maskL must equal at a boundary
+	bits       uint8       // Number of bits in target size
+	normLevel  uint8       // Normalization level (0-8)
+	version    uint8       // Algorithm version
+	seed       uint64      // Seed the table was generated from
+	rolling    RollingHash // Rolling hash function
+	scalar     bool        // Use the plain Gear loop instead of the unrolled one (WithUnroll)
+	fromStart  bool        // Hash the Gear window before minSize (WithHashFromStart)
+	fixed      bool        // Cut every maxSize bytes regardless of content (WithFixedSize)
+
+	// WithNormRegions state, used instead of maskS and maskL if nRegions is non-zero
+	regions  [maxNormRegions]normRegion
+	nRegions uint8
+
+	// HashRabin state
+	rabinTables *rabinTables // Shared tables, nil for HashGear
+	rabin       rabinWindow  // Window of the current chunk
 
 	// State
 	position uint32 // Current position within chunk
+	forced   bool   // The last boundary found was cut at maxSize
+
+	// FindBoundaryReader state
+	rStart, rEnd int    // Unconsumed bytes in the caller's buffer
+	rOffset      uint64 // Absolute offset of buf[rStart]
+	rEOF         bool   // The reader is exhausted
 }
 
 // NewChunkerCore creates a new ChunkerCore with the given options.
 // This is a zero-allocation API - the caller manages all buffers.
 func NewChunkerCore(opts ...Option) (*ChunkerCore, error) {
 	// Use stack-allocated config to avoid heap allocation
-	cfg := config{
-		minSize:    DefaultMinSize,
-		targetSize: DefaultTargetSize,
-		maxSize:    DefaultMaxSize,
-		normLevel:  DefaultNormLevel,
-		seed:       0,
-		bufferSize: DefaultBufferSize,
-	}
+	cfg := defaultConfig()
 	for _, opt := range opts {
 		if err := opt(&cfg); err != nil {
 			return nil, err
@@ -55,31 +77,82 @@ func NewChunkerCore(opts ...Option) (*ChunkerCore, error) {
 // Returns by value to allow embedding without heap allocation.
 func newChunkerCoreWithConfig(cfg *config) ChunkerCore {
 	maskS, maskL, normSize, bits := cfg.computeMasks()
+	regions, nRegions := cfg.computeRegions()
+
+	table := cfg.table
+	if table == nil {
+		generated := GenerateTable(cfg.seed)
+		table = &generated
+	}
+
+	var tables *rabinTables
+	if cfg.rolling == HashRabin {
+		tables = rabinTablesFor()
+	}
 
 	return ChunkerCore{
-		table:       generateTable(cfg.seed),
+		table:       *table,
 		fingerprint: 0,
 		minSize:     cfg.minSize,
+		targetSize:  cfg.targetSize,
 		normSize:    normSize,
 		maxSize:     cfg.maxSize,
 		maskS:       maskS,
 		maskL:       maskL,
+		matchS:      cfg.boundary & maskS,
+		matchL:      cfg.boundary & maskL,
 		bits:        bits,
 		normLevel:   cfg.normLevel,
+		version:     cfg.version,
+		seed:        cfg.seed,
+		rolling:     cfg.rolling,
+		scalar:      cfg.noUnroll,
+		fromStart:   cfg.hashFromStart,
+		fixed:       cfg.fixedSize != 0,
+		regions:     regions,
+		nRegions:    nRegions,
+		rabinTables: tables,
 		position:    0,
 	}
 }
 
+// setSizes applies the size thresholds and masks of a validated config, keeping
+// the table and the scanning state.
+func (c *ChunkerCore) setSizes(cfg *config) {
+	c.maskS, c.maskL, c.normSize, c.bits = cfg.computeMasks()
+	c.regions, c.nRegions = cfg.computeRegions()
+	c.matchS = cfg.boundary & c.maskS
+	c.matchL = cfg.boundary & c.maskL
+	c.minSize = cfg.minSize
+	c.targetSize = cfg.targetSize
+	c.maxSize = cfg.maxSize
+	c.normLevel = cfg.normLevel
+}
+
+// Clone returns an independent copy of the core, including its table, sizes,
+// masks and scanning state, so that both continue the current chunk alike: fed
+// the same data, they find the same boundaries, and each can then be fed
+// different data. Only the immutable HashRabin tables are shared. The
+// FindBoundaryReader state is copied too, so each copy must then be given its
+// own copy of the buffer.
+func (c *ChunkerCore) Clone() *ChunkerCore {
+	clone := *c
+
+	return &clone
+}
+
 // Reset resets the chunker state for processing a new stream.
 // This allows reusing the same ChunkerCore instance.
 func (c *ChunkerCore) Reset() {
 	c.fingerprint = 0
 	c.position = 0
+	c.rabin = rabinWindow{}
+	c.rStart, c.rEnd, c.rOffset, c.rEOF = 0, 0, 0, false
 }
 
 // FindBoundary scans the provided data for a chunk boundary.
 // It returns:
-//   - boundary: the index of the chunk boundary (exclusive)
+//   - boundary: the index in data of the chunk boundary (exclusive), or len(data) if not found
 //   - hash: the final Gear hash value at the boundary
 //   - found: true if a boundary was found, false if data exhausted
 //
@@ -90,6 +163,12 @@ func (c *ChunkerCore) Reset() {
 //
 // The chunker maintains state between calls, so calling FindBoundary
 // multiple times continues scanning from where the previous call left off.
+// Empty data returns (0, Fingerprint(), false) and leaves the state unchanged.
+// When no boundary is found, all of data is consumed into the current chunk
+// and the next call continues with the following bytes. A boundary found in a
+// later call is still relative to that call's data: the chunk then consists of
+// the bytes passed to the earlier calls followed by data[:boundary]. Chunk
+// sizes honor minSize and maxSize regardless of how the data is split.
 //
 // Example usage:
 //
@@ -108,52 +187,238 @@ func (c *ChunkerCore) Reset() {
 //	    }
 //	}
 //
-// FindBoundary scans the provided data for a chunk boundary.
-// It returns:
-//   - boundary: the index of the chunk boundary (exclusive)
-//   - hash: the final Gear hash value at the boundary
-//   - found: true if a boundary was found, false if data exhausted
-//
-// This is a zero-allocation API. The caller is responsible for:
-//  1. Providing the data buffer
-//  2. Tracking absolute position across multiple calls
-//  3. Handling data at chunk boundaries
-//
-// The chunker maintains state between calls, so calling FindBoundary
-// multiple times continues scanning from where the previous call left off.
+// Boundaries depend on the algorithm version selected with WithAlgorithmVersion,
+// or on the rolling hash selected with WithRollingHash.
+func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, found bool) {
+	c.forced = false
+
+	if c.fixed {
+		return c.findBoundaryFixed(data)
+	}
+
+	if c.rolling == HashRabin {
+		return c.findBoundaryRabin(data)
+	}
+
+	if c.nRegions != 0 {
+		return c.findBoundaryRegions(data)
+	}
+
+	// Released versions are frozen, so a given version always reproduces the same boundaries
+	switch c.version {
+	default: // AlgorithmV1
+		if c.scalar {
+			return c.findBoundaryScalar(data)
+		}
+
+		return c.findBoundaryV1(data)
+	}
+}
+
+// BoundaryReason tells which rule ended a chunk found by FindBoundaryEx.
+type BoundaryReason uint8
+
+const (
+	// NaturalSmall is a content-defined cut at or below normSize, matched with
+	// the small mask of the normalized region.
+	NaturalSmall BoundaryReason = iota + 1
+
+	// NaturalLarge is a content-defined cut above normSize, matched with the
+	// large mask.
+	NaturalLarge
+
+	// MaxLimit is a forced cut at maxSize where no mask matched. Such boundaries
+	// depend on where the chunk started rather than on the content, so they
+	// re-synchronize poorly after an edit.
+	MaxLimit
+)
+
+// BoundaryResult describes a boundary found by FindBoundaryEx.
+type BoundaryResult struct {
+	Offset int            // Index in data of the boundary (exclusive)
+	Hash   uint64         // Rolling hash at the boundary
+	Reason BoundaryReason // Rule that ended the chunk
+}
+
+// FindBoundaryEx is like FindBoundary but also reports why the chunk ended.
+// When no boundary is found it returns false and a zero BoundaryResult, and
+// all of data is consumed into the current chunk as with FindBoundary.
+func (c *ChunkerCore) FindBoundaryEx(data []byte) (BoundaryResult, bool) {
+	start := c.position
+
+	boundary, hash, found := c.FindBoundary(data)
+	if !found {
+		return BoundaryResult{}, false
+	}
+
+	reason := NaturalLarge
+
+	switch {
+	case c.forced:
+		reason = MaxLimit
+	case uint64(start)+uint64(boundary) <= uint64(c.normSize): //nolint:gosec // G115
+		reason = NaturalSmall
+	}
+
+	return BoundaryResult{Offset: boundary, Hash: hash, Reason: reason}, true
+}
+
+// FindBoundaryFinal is like FindBoundary but treats the end of data as the end
+// of the stream: if no boundary is found before it, the tail ends the chunk and
+// the boundary is len(data), with the current fingerprint as the hash (zero for
+// a tail shorter than minSize, which is not hashed). A loop over the last block
+// of a stream thus needs no separate branch for the final partial chunk. As
+// after FindBoundary, call Reset before the next chunk.
+func (c *ChunkerCore) FindBoundaryFinal(data []byte) (boundary int, hash uint64) {
+	boundary, hash, found := c.FindBoundary(data)
+	if !found {
+		c.position = 0
+	}
+
+	return boundary, hash
+}
+
+// FindAllBoundaries scans all of data, calling fn with the offset and length in data
+// of each complete chunk and its hash, and resetting the chunker between chunks.
+// It returns the length of the trailing bytes that did not end a chunk.
 //
-// Example usage:
+// Like FindBoundary, the trailing bytes are consumed into the current chunk: the
+// next call continues that chunk with the following data, and the first chunk it
+// reports also contains the trailing bytes of this call. Call Reset to discard them.
+func (c *ChunkerCore) FindAllBoundaries(data []byte, fn func(offset, length int, hash uint64)) int {
+	offset := 0
+
+	for offset < len(data) {
+		boundary, hash, found := c.FindBoundary(data[offset:])
+		if !found {
+			break
+		}
+
+		fn(offset, boundary, hash)
+
+		offset += boundary
+		c.Reset()
+	}
+
+	return len(data) - offset
+}
+
+// AppendBoundaries scans all of data like FindAllBoundaries, appending the end
+// offset in data of each complete chunk to dst, and returns the extended slice
+// and the number of bytes in complete chunks, which is the last offset appended
+// or 0. Chunks cut at the hard maxSize limit are included like any other.
 //
-//	core := NewChunkerCore(WithTargetSize(64*1024))
-//	buf := make([]byte, 1*1024*1024)
+// The trailing data[consumed:] is kept in the chunker state as with
+// FindAllBoundaries: the next call continues that chunk. Reusing dst across
+// calls makes AppendBoundaries allocation-free.
+func (c *ChunkerCore) AppendBoundaries(dst []int, data []byte) (boundaries []int, consumed int) {
+	for consumed < len(data) {
+		boundary, _, found := c.FindBoundary(data[consumed:])
+		if !found {
+			break
+		}
+
+		consumed += boundary
+		dst = append(dst, consumed)
+		c.Reset()
+	}
+
+	return dst, consumed
+}
+
+// FindBoundaryReader reads from r into buf and returns the next chunk, with
+// Chunk.Data pointing into buf. It returns io.EOF when r is exhausted.
 //
-//	for {
-//	    n, _ := reader.Read(buf)
-//	    if n == 0 {
-//	        break
-//	    }
-//	    boundary, hash, found := core.FindBoundary(buf[:n])
-//	    if found {
-//	        processChunk(buf[:boundary], hash)
-//	        // Continue with remaining data: buf[boundary:n]
-//	    }
-//	}
+// buf is scratch space owned by the caller: it must hold at least maxSize bytes,
+// otherwise ErrBufferTooSmall is returned, and the same buffer must be passed on
+// every call for a stream since it carries the bytes read past the previous chunk.
+// Chunk.Data is valid until the next call. Chunk.Last is only reliable if buf is
+// larger than maxSize. Call Reset before starting a new stream.
+// FindBoundaryReader does not allocate.
+func (c *ChunkerCore) FindBoundaryReader(r io.Reader, buf []byte) (Chunk, error) {
+	maxSize := int(c.maxSize)
+	if len(buf) < maxSize {
+		return Chunk{}, fmt.Errorf("%w: buffer (%d), maxSize (%d)", ErrBufferTooSmall, len(buf), maxSize)
+	}
+
+	if c.rEnd-c.rStart <= maxSize && !c.rEOF {
+		// Move the unconsumed bytes to the front and fill the rest of buf
+		c.rEnd = copy(buf, buf[c.rStart:c.rEnd])
+		c.rStart = 0
+
+		n, err := io.ReadFull(r, buf[c.rEnd:])
+		c.rEnd += n
+
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			c.rEOF = true
+		} else if err != nil {
+			return Chunk{}, &ReadError{Offset: c.rOffset + uint64(c.rEnd-c.rStart), Err: err} //nolint:gosec // G115
+		}
+	}
+
+	if c.rStart == c.rEnd {
+		return Chunk{}, io.EOF
+	}
+
+	available := buf[c.rStart:c.rEnd]
+
+	boundary, hash, found := c.FindBoundary(available)
+	if !found {
+		// Only possible at EOF: the rest of the stream is the final chunk
+		boundary = len(available)
+	}
+
+	chunk := Chunk{
+		Offset: c.rOffset,
+		Length: uint32(boundary), //nolint:gosec // G115
+		Hash:   hash,
+		Data:   available[:boundary],
+		Last:   c.rEOF && boundary == len(available),
+	}
+
+	c.rStart += boundary
+	c.rOffset += uint64(boundary) //nolint:gosec // G115
+	c.fingerprint = 0
+	c.position = 0
+
+	return chunk, nil
+}
+
+// findBoundaryV1 implements FindBoundary for AlgorithmV1.
+// Do not change the boundaries it produces; add a new version instead.
 //
 //nolint:nestif
-func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, found bool) {
+func (c *ChunkerCore) findBoundaryV1(data []byte) (boundary int, hash uint64, found bool) {
 	dataLen := len(data)
 	if dataLen == 0 {
 		return 0, c.fingerprint, false
 	}
 
-	// Capture state into local variables (CPU registers)
+	// Fast path for data ending before minSize, such as a small file: nothing
+	// is hashed before minSize, so only the position advances
+	if uint64(c.position)+uint64(dataLen) <= uint64(c.minSize) {
+		if c.fromStart {
+			c.fingerprint = c.warmUp(c.fingerprint, data, int(c.position))
+		}
+
+		c.position += uint32(dataLen) //nolint:gosec // G115
+
+		return dataLen, c.fingerprint, false
+	}
+
+	// Capture state into local variables (CPU registers).
+	// Positions are relative to the start of data; the size thresholds are
+	// shifted by the bytes of this chunk consumed in previous calls.
 	fp := c.fingerprint
-	pos := int(c.position)
-	minSize := int(c.minSize)
-	normSize := int(c.normSize)
-	maxSize := int(c.maxSize)
+	start := int(c.position)
+	pos := 0
+	minSize := int(c.minSize) - start
+	normSize := int(c.normSize) - start
+	maxSize := int(c.maxSize) - start
 	maskS := c.maskS
 	maskL := c.maskL
+	matchS := c.matchS
+	matchL := c.matchL
 	// We don't capture table as it's an array and would be copied.
 	// We access it directly via pointer receiver which is fast.
 
@@ -164,6 +429,10 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			skip = dataLen
 		}
 
+		if c.fromStart {
+			fp = c.warmUp(fp, data[:skip], start)
+		}
+
 		pos += skip
 		data = data[skip:]
 		dataLen -= skip
@@ -178,11 +447,24 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 		}
 
 		i := 0
+		if end >= gearScanMin {
+			// Long regions are hashed in parallel lanes where supported
+			n, h, found := gearScan(&c.table, data[:end], fp, maskS, matchS)
+			if found {
+				c.fingerprint = h
+				c.position = 0
+
+				return pos + n, h, true
+			}
+
+			fp, i = h, end
+		}
+
 		// Unroll loop 8x for Phase 1
 		for ; i+8 <= end; i += 8 {
 			// 1
 			fp = (fp << 1) + c.table[data[i]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -190,7 +472,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 2
 			fp = (fp << 1) + c.table[data[i+1]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -198,7 +480,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 3
 			fp = (fp << 1) + c.table[data[i+2]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -206,7 +488,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 4
 			fp = (fp << 1) + c.table[data[i+3]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -214,7 +496,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 5
 			fp = (fp << 1) + c.table[data[i+4]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -222,7 +504,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 6
 			fp = (fp << 1) + c.table[data[i+5]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -230,7 +512,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 7
 			fp = (fp << 1) + c.table[data[i+6]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -238,7 +520,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 8
 			fp = (fp << 1) + c.table[data[i+7]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -249,7 +531,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 		// Handle remaining bytes for Phase 1
 		for ; i < end; i++ {
 			fp = (fp << 1) + c.table[data[i]]
-			if (fp & maskS) == 0 {
+			if (fp & maskS) == matchS {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -271,11 +553,24 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 		}
 
 		i := 0
+		if end >= gearScanMin {
+			// Long regions are hashed in parallel lanes where supported
+			n, h, found := gearScan(&c.table, data[:end], fp, maskL, matchL)
+			if found {
+				c.fingerprint = h
+				c.position = 0
+
+				return pos + n, h, true
+			}
+
+			fp, i = h, end
+		}
+
 		// Unroll loop 8x for Phase 2
 		for ; i+8 <= end; i += 8 {
 			// 1
 			fp = (fp << 1) + c.table[data[i]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -283,7 +578,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 2
 			fp = (fp << 1) + c.table[data[i+1]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -291,7 +586,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 3
 			fp = (fp << 1) + c.table[data[i+2]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -299,7 +594,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 4
 			fp = (fp << 1) + c.table[data[i+3]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -307,7 +602,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 5
 			fp = (fp << 1) + c.table[data[i+4]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -315,7 +610,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 6
 			fp = (fp << 1) + c.table[data[i+5]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -323,7 +618,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 7
 			fp = (fp << 1) + c.table[data[i+6]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -331,7 +626,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 			}
 			// 8
 			fp = (fp << 1) + c.table[data[i+7]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -342,7 +637,7 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 		// Handle remaining bytes for Phase 2
 		for ; i < end; i++ {
 			fp = (fp << 1) + c.table[data[i]]
-			if (fp & maskL) == 0 {
+			if (fp & maskL) == matchL {
 				c.fingerprint = fp
 				c.position = 0
 
@@ -359,13 +654,14 @@ func (c *ChunkerCore) FindBoundary(data []byte) (boundary int, hash uint64, foun
 	if pos >= maxSize {
 		c.fingerprint = fp
 		c.position = 0 // Reset for next chunk
+		c.forced = true
 
 		return pos, fp, true
 	}
 
 	// No boundary found, save state for next call
 	c.fingerprint = fp
-	c.position = uint32(pos) //nolint:gosec // G115
+	c.position = uint32(start + pos) //nolint:gosec // G115
 
 	return pos, fp, false
 }
@@ -386,6 +682,11 @@ func (c *ChunkerCore) MinSize() uint32 {
 	return c.minSize
 }
 
+// TargetSize returns the target chunk size.
+func (c *ChunkerCore) TargetSize() uint32 {
+	return c.targetSize
+}
+
 // MaxSize returns the maximum chunk size.
 func (c *ChunkerCore) MaxSize() uint32 {
 	return c.maxSize
@@ -395,3 +696,14 @@ func (c *ChunkerCore) MaxSize() uint32 {
 func (c *ChunkerCore) NormSize() uint32 {
 	return c.normSize
 }
+
+// NormLevel returns the normalization level.
+func (c *ChunkerCore) NormLevel() uint8 {
+	return c.normLevel
+}
+
+// Seed returns the seed the Gear hash table was generated from, or 0 for the
+// default table or one installed with WithTable.
+func (c *ChunkerCore) Seed() uint64 {
+	return c.seed
+}
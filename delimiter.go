@@ -0,0 +1,30 @@
+package fastcdc
+
+import "bytes"
+
+// moveToDelimiter returns the end of the chunk ending at boundary in data moved
+// to just after the nearest preferred delimiter within the window, keeping the
+// chunk within minSize and maxSize (WithPreferredDelimiter). A boundary forced
+// at maxSize can only move back.
+func (c *Chunker) moveToDelimiter(data []byte, boundary int) int {
+	window := c.cfg.delimWindow
+	best, dist := boundary, window+1
+
+	// The nearest delimiter at or before boundary ends the chunk at lo+i+1
+	lo := max(boundary-window, int(c.core.minSize), 1) - 1
+	if lo < boundary {
+		if i := bytes.LastIndexByte(data[lo:boundary], c.cfg.delimiter); i >= 0 {
+			best, dist = lo+i+1, boundary-(lo+i+1)
+		}
+	}
+
+	// The nearest one after it ends the chunk at boundary+i+1
+	hi := min(boundary+window, int(c.core.maxSize), len(data))
+	if hi > boundary {
+		if i := bytes.IndexByte(data[boundary:hi], c.cfg.delimiter); i >= 0 && i+1 < dist {
+			best = boundary + i + 1
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,61 @@
+package fastcdc
+
+// DeltaStats measures how many chunks of a new version of a stream are
+// identical to chunks of a previous version, which is what a sync or backup
+// tool can skip transferring or storing.
+type DeltaStats struct {
+	Chunks       int    // Number of chunks in the new version
+	ReusedChunks int    // Chunks of the new version also present in the previous one
+	Bytes        uint64 // Total size of the new version in bytes
+	ReusedBytes  uint64 // Total size of the reused chunks in bytes
+}
+
+// Ratio returns the fraction of bytes of the new version covered by reused
+// chunks, between 0 and 1.
+func (s DeltaStats) Ratio() float64 {
+	if s.Bytes == 0 {
+		return 0
+	}
+
+	return float64(s.ReusedBytes) / float64(s.Bytes)
+}
+
+// deltaKey identifies a chunk for Delta.
+type deltaKey struct {
+	length uint32
+	hash   uint64
+	digest string
+}
+
+// Delta compares the chunks of two versions of a stream, such as two revisions
+// of a file chunked with the same options, and reports how many chunks of next
+// already exist in prev.
+//
+// FastCDC re-synchronizes on its own: after an insertion, deletion or change,
+// boundaries fall back into place within a chunk or two, so unchanged regions
+// produce identical chunks and no hint from prev is needed while chunking next.
+// Delta surfaces how well that works for a given edit and configuration.
+//
+// Chunks match on length, Gear hash and, when present, Chunk.Digest. The Gear
+// hash only depends on the last bytes of a chunk, so enable WithChunkDigest for
+// an exact comparison. Only the metadata of the chunks is used, not Data.
+func Delta(prev, next []Chunk) DeltaStats {
+	seen := make(map[deltaKey]struct{}, len(prev))
+	for _, c := range prev {
+		seen[deltaKey{length: c.Length, hash: c.Hash, digest: string(c.Digest)}] = struct{}{}
+	}
+
+	var s DeltaStats
+
+	for _, c := range next {
+		s.Chunks++
+		s.Bytes += uint64(c.Length)
+
+		if _, ok := seen[deltaKey{length: c.Length, hash: c.Hash, digest: string(c.Digest)}]; ok {
+			s.ReusedChunks++
+			s.ReusedBytes += uint64(c.Length)
+		}
+	}
+
+	return s
+}
@@ -0,0 +1,91 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// chunkDigests chunks data and returns chunk metadata with SHA-256 digests.
+func chunkDigests(t *testing.T, data []byte) []fastcdc.Chunk {
+	t.Helper()
+
+	c, err := fastcdc.NewChunker(bytes.NewReader(data),
+		fastcdc.WithMinSize(4*1024),
+		fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithChunkDigest(sha256.New),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var chunks []fastcdc.Chunk
+
+	for {
+		chunk, err := c.Next()
+		if errors.Is(err, io.EOF) {
+			return chunks
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		chunk.Data = nil
+		chunks = append(chunks, chunk)
+	}
+}
+
+func TestDelta(t *testing.T) {
+	t.Parallel()
+
+	v1 := randBytes(4*1024*1024, 1269)
+	mid := len(v1) / 2
+
+	tests := []struct {
+		name string
+		v2   []byte
+	}{
+		{"insert", append(append(append([]byte{}, v1[:mid]...), randBytes(100, 1)...), v1[mid:]...)},
+		{"delete", append(append([]byte{}, v1[:mid]...), v1[mid+100:]...)},
+		{"modify", func() []byte {
+			v2 := bytes.Clone(v1)
+			v2[mid] ^= 0xff
+
+			return v2
+		}()},
+	}
+
+	prev := chunkDigests(t, v1)
+
+	if s := fastcdc.Delta(prev, prev); s.Ratio() != 1 || s.ReusedChunks != len(prev) {
+		t.Errorf("Identical versions: got %+v, want all chunks reused", s)
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s := fastcdc.Delta(prev, chunkDigests(t, tt.v2))
+
+			// A local edit must only affect the chunks around it
+			if changed := s.Chunks - s.ReusedChunks; changed > 3 {
+				t.Errorf("%d of %d chunks changed after a single edit", changed, s.Chunks)
+			}
+
+			if s.Ratio() < 0.95 {
+				t.Errorf("Dedup ratio %.3f, want >= 0.95", s.Ratio())
+			}
+
+			t.Logf("%s: %d/%d chunks reused, dedup ratio %.4f", tt.name, s.ReusedChunks, s.Chunks, s.Ratio())
+		})
+	}
+
+	if s := fastcdc.Delta(nil, nil); s.Ratio() != 0 {
+		t.Errorf("Empty versions: ratio %f, want 0", s.Ratio())
+	}
+}
@@ -0,0 +1,110 @@
+package fastcdc
+
+import "fmt"
+
+// MaskMatchStats chunks data from a fresh state and reports how the two masks behave on it.
+// It returns:
+//   - smallMatches: hashed positions where (fingerprint & maskS) matches the boundary value
+//   - largeMatches: hashed positions where (fingerprint & maskL) matches the boundary value
+//   - forced: cuts made at maxSize because no boundary was found
+//
+// Both masks are tested at every hashed position, in either region, so the counts
+// reflect candidate matches rather than just the cuts that were taken. Chunking
+// itself follows the normal rules, including the region masks of
+// WithNormRegions and the warm-up of WithHashFromStart, and the trailing partial
+// chunk is not a cut. With WithFixedSize no fingerprint is computed, so both
+// match counts are zero and every chunk is a forced cut.
+//
+// This is a diagnostic for studying normalization on a dataset. It is much slower
+// than FindBoundary and does not modify the ChunkerCore state.
+func (c *ChunkerCore) MaskMatchStats(data []byte) (smallMatches, largeMatches, forced int) {
+	if c.fixed {
+		return 0, 0, len(data) / int(c.maxSize)
+	}
+
+	var (
+		fp    uint64
+		pos   uint32
+		rabin rabinWindow
+	)
+
+	for _, b := range data {
+		pos++
+
+		if c.rolling == HashRabin {
+			// Hash the last window before minSize, as findBoundaryRabin does
+			if pos+rabinWindowSize > c.minSize {
+				fp = rabin.roll(c.rabinTables, b)
+			}
+
+			if pos <= c.minSize {
+				continue
+			}
+		} else {
+			// Phase 0: no hashing below minSize, except for the last gearWindow
+			// bytes with WithHashFromStart, as warmUp does
+			if pos > c.minSize || c.fromStart && pos+gearWindow > c.minSize {
+				fp = (fp << 1) + c.table[b]
+			}
+
+			if pos <= c.minSize {
+				continue
+			}
+		}
+
+		if fp&c.maskS == c.matchS {
+			smallMatches++
+		}
+
+		if fp&c.maskL == c.matchL {
+			largeMatches++
+		}
+
+		mask, match := c.maskL, c.matchL
+		if c.nRegions != 0 {
+			mask, match = c.regionMask(int(pos) - 1)
+		} else if pos <= c.normSize {
+			mask, match = c.maskS, c.matchS
+		}
+
+		// The Gear fingerprint carries over into the next chunk, as in FindBoundary
+		switch {
+		case fp&mask == match:
+			pos, rabin = 0, rabinWindow{}
+		case pos >= c.maxSize:
+			forced++
+			pos, rabin = 0, rabinWindow{}
+		}
+	}
+
+	return smallMatches, largeMatches, forced
+}
+
+// String describes the sizes, masks and scanning state of the core for debugging
+// and issue reports, for example:
+//
+//	ChunkerCore{hash=gear min=16384 norm=28672 max=262144 maskS=0x7fff maskL=0xffff position=20000 fingerprint=0x0cd67cc53bdb4abc}
+//
+// The format is meant for people and may change between releases.
+func (c *ChunkerCore) String() string {
+	rolling := "gear"
+	if c.rolling == HashRabin {
+		rolling = "rabin"
+	}
+
+	return fmt.Sprintf("ChunkerCore{hash=%s min=%d norm=%d max=%d maskS=%#x maskL=%#x position=%d fingerprint=%#016x}",
+		rolling, c.minSize, c.normSize, c.maxSize, c.maskS, c.maskL, c.position, c.fingerprint)
+}
+
+// String describes the stream position and buffer state of the chunker and its
+// core for debugging and issue reports. The buffer is "bufio" when the chunker
+// peeks into a *bufio.Reader, with no cursor of its own. As for ChunkerCore, the
+// format may change between releases.
+func (c *Chunker) String() string {
+	buffer := "bufio"
+	if c.br == nil {
+		buffer = fmt.Sprintf("cursor=%d buffered=%d size=%d", c.cursor, len(c.buf)-c.cursor, cap(c.buf))
+	}
+
+	return fmt.Sprintf("Chunker{offset=%d %s eof=%t core=%s}", c.offset, buffer, c.eof, c.core.String())
+}
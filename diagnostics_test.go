@@ -0,0 +1,153 @@
+package fastcdc_test
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestMaskMatchStats verifies mask match counts against analytical expectations.
+func TestMaskMatchStats(t *testing.T) {
+	t.Parallel()
+
+	// Deterministic data so the forced-cut comparison below is stable
+	data := randBytes(4*1024*1024, 1220)
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(1024),
+		fastcdc.WithTargetSize(4096),
+		fastcdc.WithMaxSize(8192),
+	}
+
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	small, large, forced := core.MaskMatchStats(data)
+
+	if core.Position() != 0 || core.Fingerprint() != 0 {
+		t.Error("MaskMatchStats modified the core state")
+	}
+
+	// Every maskL match is also a maskS match since maskS has one bit fewer,
+	// and on random data maskS should match about twice as often.
+	if large == 0 || small < large {
+		t.Fatalf("Unexpected counts: small=%d large=%d", small, large)
+	}
+
+	ratio := float64(small) / float64(large)
+	if ratio < 1.5 || ratio > 2.5 {
+		t.Errorf("maskS/maskL match ratio = %.2f, want ~2", ratio)
+	}
+
+	if want := forcedCuts(t, data, opts...); forced != want {
+		t.Errorf("forced = %d, want %d", forced, want)
+	}
+
+	t.Logf("small=%d large=%d forced=%d ratio=%.2f", small, large, forced, ratio)
+}
+
+// forcedCuts returns the number of chunks of data cut at maxSize by
+// FindBoundaryEx.
+func forcedCuts(t *testing.T, data []byte, opts ...fastcdc.Option) int {
+	t.Helper()
+
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	forced := 0
+
+	for offset := 0; offset < len(data); {
+		result, found := core.FindBoundaryEx(data[offset:])
+		if !found {
+			break
+		}
+
+		if result.Reason == fastcdc.MaxLimit {
+			forced++
+		}
+
+		offset += result.Offset
+	}
+
+	return forced
+}
+
+// TestMaskMatchStatsOptions verifies that MaskMatchStats reports the forced
+// cuts of the real chunking under options changing the boundary rules.
+func TestMaskMatchStatsOptions(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1311)
+
+	sizes := []fastcdc.Option{
+		fastcdc.WithMinSize(1024),
+		fastcdc.WithTargetSize(4096),
+		fastcdc.WithMaxSize(8192),
+	}
+
+	tests := []struct {
+		name string
+		opts []fastcdc.Option
+	}{
+		{"Default", sizes},
+		{"NormRegions", append([]fastcdc.Option{fastcdc.WithNormRegions(4)}, sizes...)},
+		{"Rabin", append([]fastcdc.Option{fastcdc.WithRollingHash(fastcdc.HashRabin)}, sizes...)},
+		{"HashFromStart", append([]fastcdc.Option{fastcdc.WithHashFromStart()}, sizes...)},
+		{"FixedSize", []fastcdc.Option{fastcdc.WithFixedSize(4096)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			core, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, _, forced := core.MaskMatchStats(data)
+			if want := forcedCuts(t, data, tt.opts...); forced != want {
+				t.Errorf("forced = %d, want %d", forced, want)
+			}
+		})
+	}
+}
+
+func TestString(t *testing.T) {
+	t.Parallel()
+
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(fastcdc.HashRabin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core.FindBoundary(randBytes(16500, 1320))
+
+	for _, want := range []string{"hash=rabin", "min=16384", "max=262144", "position=16500"} {
+		if !strings.Contains(core.String(), want) {
+			t.Errorf("ChunkerCore.String() = %q, missing %q", core.String(), want)
+		}
+	}
+
+	c := mustChunker(t, bytes.NewReader(randBytes(100*1024, 1320)))
+	if _, err := c.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, want := range []string{"offset=", "cursor=", "eof=true", "core=ChunkerCore{hash=gear", "position=0"} {
+		if !strings.Contains(c.String(), want) {
+			t.Errorf("Chunker.String() = %q, missing %q", c.String(), want)
+		}
+	}
+
+	if s := mustChunker(t, bufio.NewReaderSize(bytes.NewReader(nil), 512*1024)).String(); !strings.Contains(s, "bufio") {
+		t.Errorf("Chunker.String() = %q over a bufio.Reader", s)
+	}
+}
@@ -0,0 +1,229 @@
+package fastcdc_test
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestTinyInputs pins the contract for empty and one-byte inputs across the
+// APIs: an empty input yields no chunks, and a one-byte input yields a single
+// final chunk of length 1 at offset 0.
+func TestTinyInputs(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	for _, input := range [][]byte{{}, {0x42}} {
+		// check verifies the chunks produced for input by one API
+		check := func(api string, chunks []fastcdc.Chunk) {
+			t.Helper()
+
+			if len(input) == 0 {
+				if len(chunks) != 0 {
+					t.Errorf("%s: empty input produced %d chunks, want none", api, len(chunks))
+				}
+
+				return
+			}
+
+			if len(chunks) != 1 {
+				t.Fatalf("%s: one-byte input produced %d chunks, want 1", api, len(chunks))
+			}
+
+			chunk := chunks[0]
+			if chunk.Offset != 0 || chunk.Length != 1 || !bytes.Equal(chunk.Data, input) {
+				t.Errorf("%s: got chunk at %d of length %d with data %x, want the byte at 0", api, chunk.Offset,
+					chunk.Length, chunk.Data)
+			}
+		}
+
+		readers := map[string]io.Reader{
+			"reader":   bytes.NewReader(input),
+			"bufio":    bufio.NewReaderSize(bytes.NewReader(input), 512*1024),
+			"one byte": iotest.OneByteReader(bytes.NewReader(input)),
+		}
+
+		for name, r := range readers {
+			var chunks []fastcdc.Chunk
+
+			err := mustChunker(t, r, fastcdc.WithCopyData()).Process(func(chunk fastcdc.Chunk) error {
+				if !chunk.Last {
+					t.Errorf("%s: chunk not marked Last", name)
+				}
+
+				chunks = append(chunks, chunk)
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			check("Chunker "+name, chunks)
+		}
+
+		c, err := fastcdc.NewChunkerFromBytes(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var all []fastcdc.Chunk
+
+		for chunk, err := range c.All() {
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			all = append(all, chunk)
+		}
+
+		check("NewChunkerFromBytes", all)
+
+		if _, err := c.Next(); err != io.EOF { //nolint:errorlint // io.EOF must not be wrapped
+			t.Errorf("Next after the end = %v, want io.EOF", err)
+		}
+
+		// FindBoundaryReader
+		core, err := fastcdc.NewChunkerCore()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var (
+			fromReader []fastcdc.Chunk
+			buf        = make([]byte, fastcdc.DefaultMaxSize+1)
+			r          = bytes.NewReader(input)
+		)
+
+		for {
+			chunk, err := core.FindBoundaryReader(r, buf)
+			if err == io.EOF { //nolint:errorlint // io.EOF must not be wrapped
+				break
+			}
+
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			fromReader = append(fromReader, chunk)
+		}
+
+		check("FindBoundaryReader", fromReader)
+
+		// StreamChunker
+		s, err := fastcdc.NewStreamChunker()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		s.Feed(input)
+
+		var pushed []fastcdc.Chunk
+
+		for chunk, ok := s.Pull(); ok; chunk, ok = s.Pull() {
+			pushed = append(pushed, chunk)
+		}
+
+		if final := s.Finish(); final.Length > 0 {
+			pushed = append(pushed, final)
+		}
+
+		check("StreamChunker", pushed)
+
+		// Files
+		path := filepath.Join(dir, string(rune('0'+len(input))))
+		if err := os.WriteFile(path, input, 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		var mapped []fastcdc.Chunk
+
+		err = fastcdc.ChunkMmap(path, func(chunk fastcdc.Chunk) error {
+			chunk.Data = bytes.Clone(chunk.Data)
+			mapped = append(mapped, chunk)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		check("ChunkMmap", mapped)
+
+		parallel, err := fastcdc.ChunkFileParallel(path, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		check("ChunkFileParallel", parallel)
+
+		boundaries, err := fastcdc.GoldenBoundaries(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(boundaries) != len(input) || (len(input) == 1 && boundaries[0] != 1) {
+			t.Errorf("GoldenBoundaries(%x) = %v", input, boundaries)
+		}
+	}
+}
+
+// TestChunkerCoreTinyData tests FindBoundary and the helpers built on it with
+// empty and one-byte data.
+func TestChunkerCoreTinyData(t *testing.T) {
+	t.Parallel()
+
+	for _, rolling := range []fastcdc.RollingHash{fastcdc.HashGear, fastcdc.HashRabin} {
+		core, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(rolling))
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// Empty data consumes nothing and finds nothing, however often
+		for range 3 {
+			if boundary, hash, found := core.FindBoundary(nil); boundary != 0 || hash != 0 || found {
+				t.Errorf("rolling %d: FindBoundary(nil) = %d, %x, %v", rolling, boundary, hash, found)
+			}
+		}
+
+		if core.Position() != 0 || core.Fingerprint() != 0 {
+			t.Errorf("rolling %d: empty data changed the state", rolling)
+		}
+
+		// One byte joins the current chunk
+		if boundary, _, found := core.FindBoundary([]byte{1}); boundary != 1 || found {
+			t.Errorf("rolling %d: FindBoundary of one byte = %d, %v, want 1, false", rolling, boundary, found)
+		}
+
+		if core.Position() != 1 {
+			t.Errorf("rolling %d: Position() = %d after one byte, want 1", rolling, core.Position())
+		}
+
+		core.Reset()
+
+		if core.Position() != 0 || core.Fingerprint() != 0 {
+			t.Errorf("rolling %d: Reset left position %d, fingerprint %x", rolling, core.Position(), core.Fingerprint())
+		}
+
+		if tail := core.FindAllBoundaries(nil, func(_, _ int, _ uint64) { t.Error("unexpected chunk") }); tail != 0 {
+			t.Errorf("rolling %d: FindAllBoundaries(nil) left %d bytes", rolling, tail)
+		}
+
+		if tail := core.FindAllBoundaries([]byte{1}, func(_, _ int, _ uint64) { t.Error("unexpected chunk") }); tail != 1 {
+			t.Errorf("rolling %d: FindAllBoundaries of one byte left %d bytes, want 1", rolling, tail)
+		}
+
+		core.Reset()
+
+		if boundaries, consumed := core.AppendBoundaries(nil, []byte{1}); len(boundaries) != 0 || consumed != 0 {
+			t.Errorf("rolling %d: AppendBoundaries of one byte = %v, %d", rolling, boundaries, consumed)
+		}
+	}
+}
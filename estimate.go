@@ -0,0 +1,65 @@
+package fastcdc
+
+import (
+	"math"
+	"math/bits"
+)
+
+// EstimatedChunkCount estimates how many chunks an input of fileSize bytes
+// produces with opts, for example to pre-size a manifest with
+// make([]ChunkRef, 0, n). It divides fileSize by the expected chunk size,
+// rounding up: the mean of a chunk's length when every position past minSize
+// ends it with the probability of its mask, and maxSize ends it otherwise, as
+// for random data. This is a heuristic, not a bound: on random data it is
+// typically a few percent high, which suits preallocation, while data with
+// little entropy or repeated patterns can chunk quite differently. With
+// WithFixedSize it is exact. It returns the errors of NewChunkerCore.
+func EstimatedChunkCount(fileSize int64, opts ...Option) (int, error) {
+	core, err := NewChunkerCore(opts...)
+	if err != nil {
+		return 0, err
+	}
+
+	if fileSize <= 0 {
+		return 0, nil
+	}
+
+	return int(math.Ceil(float64(fileSize) / core.expectedChunkSize())), nil
+}
+
+// expectedChunkSize returns the mean chunk length for random data.
+func (c *ChunkerCore) expectedChunkSize() float64 {
+	if c.fixed {
+		return float64(c.maxSize)
+	}
+
+	type region struct {
+		end  uint32
+		mask uint64
+	}
+
+	regions := []region{{c.normSize, c.maskS}, {c.maxSize, c.maskL}}
+	if c.nRegions != 0 {
+		regions = regions[:0]
+		for _, r := range c.regions[:c.nRegions] {
+			regions = append(regions, region{r.end, r.mask})
+		}
+	}
+
+	// Each region adds the expected bytes before a cut, given that none came before
+	mean, survive := float64(c.minSize), 1.0
+	start := c.minSize
+
+	for _, r := range regions {
+		end := min(max(r.end, start), c.maxSize)
+		n := float64(end - start)
+		p := math.Ldexp(1, -bits.OnesCount64(r.mask)) // Probability of a cut at each position
+
+		q := math.Pow(1-p, n)
+		mean += survive * (1 - q) / p
+		survive *= q
+		start = end
+	}
+
+	return mean
+}
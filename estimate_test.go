@@ -0,0 +1,61 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestEstimatedChunkCount(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(16*1024*1024, 1327)
+
+	for _, opts := range [][]fastcdc.Option{
+		{},
+		{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(64 * 1024)},
+		{fastcdc.WithNormalization(0)},
+		{fastcdc.WithNormRegions(4)},
+		{fastcdc.WithMinSize(1024), fastcdc.WithTargetSize(64 * 1024), fastcdc.WithMaxSize(80 * 1024)}, // Often at maxSize
+	} {
+		var count int
+
+		err := mustChunker(t, bytes.NewReader(data), opts...).Process(func(fastcdc.Chunk) error {
+			count++
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		estimate, err := fastcdc.EstimatedChunkCount(int64(len(data)), opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if float64(estimate) < 0.9*float64(count) || float64(estimate) > 1.1*float64(count) {
+			t.Errorf("estimated %d chunks, got %d", estimate, count)
+		}
+	}
+
+	for _, tt := range []struct {
+		size int64
+		opts []fastcdc.Option
+		want int
+	}{
+		{0, nil, 0},
+		{1, nil, 1},
+		{10*1000 + 500, []fastcdc.Option{fastcdc.WithFixedSize(1000)}, 11},
+	} {
+		if got, err := fastcdc.EstimatedChunkCount(tt.size, tt.opts...); err != nil || got != tt.want {
+			t.Errorf("EstimatedChunkCount(%d) = %d, %v, want %d", tt.size, got, err, tt.want)
+		}
+	}
+
+	if _, err := fastcdc.EstimatedChunkCount(1, fastcdc.WithMinSize(0)); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("got %v, want ErrInvalidMinSize", err)
+	}
+}
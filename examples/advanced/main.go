@@ -32,31 +32,19 @@ func main() {
 	offset := 0
 
 	for offset < len(data) {
-		boundary, hash, found := core.FindBoundary(data[offset:])
+		// The end of data is the end of the stream, so the tail is a chunk too
+		boundary, hash := core.FindBoundaryFinal(data[offset:])
 
-		if found {
-			chunkCount++
-			chunkSize := uint32(boundary) //nolint:gosec // G115
-			totalSize += uint64(chunkSize)
+		chunkCount++
+		chunkSize := uint32(boundary) //nolint:gosec // G115
+		totalSize += uint64(chunkSize)
 
-			fmt.Printf("Chunk %3d: offset=%8d length=%6d hash=%016x\n",
-				chunkCount, offset, chunkSize, hash)
+		fmt.Printf("Chunk %3d: offset=%8d length=%6d hash=%016x\n",
+			chunkCount, offset, chunkSize, hash)
 
-			offset += int(chunkSize)
+		offset += int(chunkSize)
 
-			core.Reset()
-		} else {
-			// Handle final partial chunk
-			remaining := len(data) - offset
-			if remaining > 0 {
-				chunkCount++
-				totalSize += uint64(remaining)
-				fmt.Printf("Chunk %3d: offset=%8d length=%6d (final)\n",
-					chunkCount, offset, remaining)
-			}
-
-			break
-		}
+		core.Reset()
 	}
 
 	fmt.Printf("\nTotal: %d chunks, %d bytes\n", chunkCount, totalSize)
@@ -65,6 +53,6 @@ func main() {
 		fmt.Printf("Average chunk size: %d bytes\n", totalSize/uint64(chunkCount))
 	}
 
-	fmt.Println("\nThis example uses the zero-allocation FindBoundary() API")
+	fmt.Println("\nThis example uses the zero-allocation FindBoundaryFinal() API")
 	fmt.Println("for maximum performance in tight loops.")
 }
@@ -0,0 +1,51 @@
+package fastcdc
+
+// fixedSizeConflict returns the first option set alongside WithFixedSize that
+// only applies to content-defined chunking, or "" if there is none.
+func (c *config) fixedSizeConflict() string {
+	switch {
+	case c.masks != nil:
+		return "WithMasks"
+	case c.targetBits != 0:
+		return "WithTargetBits"
+	case c.normSize != 0:
+		return "WithNormSize"
+	case c.normRegions > 2:
+		return "WithNormRegions"
+	case c.normLevel != DefaultNormLevel:
+		return "WithNormalization"
+	case c.normStrength != DefaultNormStrength:
+		return "WithNormalizationStrength"
+	case c.maxRatio != 0:
+		return "WithMaxChunkSizeRatio"
+	case c.rolling != HashGear:
+		return "WithRollingHash"
+	case c.adaptive:
+		return "WithAdaptiveTarget"
+	case c.minChunks > 1:
+		return "WithMinChunkCount"
+	case c.hashFromStart:
+		return "WithHashFromStart"
+	case c.delimWindow != 0:
+		return "WithPreferredDelimiter"
+	}
+
+	return ""
+}
+
+// findBoundaryFixed implements FindBoundary for WithFixedSize: the chunk ends
+// after maxSize bytes, whatever their content. No fingerprint is computed, so
+// the hash is always zero.
+func (c *ChunkerCore) findBoundaryFixed(data []byte) (boundary int, hash uint64, found bool) {
+	need := int(c.maxSize) - int(c.position)
+	if len(data) >= need {
+		c.position = 0
+		c.forced = true
+
+		return need, 0, true
+	}
+
+	c.position += uint32(len(data)) //nolint:gosec // G115
+
+	return len(data), 0, false
+}
@@ -0,0 +1,79 @@
+package fastcdc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+)
+
+// ErrEmptyFrameMagic is returned by NewFrameAwareChunker when the frame magic is empty.
+var ErrEmptyFrameMagic = errors.New("frame magic must not be empty")
+
+// NewFrameAwareChunker creates a Chunker that, in addition to content-defined
+// boundaries, cuts before every occurrence of frameMagic, so that each frame of
+// a compressed stream starts a fresh chunk. Streams that differ in how they are
+// split into frames then still share the chunks of identical frames. Use the
+// magic number of the format, such as 0x28 0xB5 0x2F 0xFD for zstd frames or
+// 0x1F 0x8B for gzip members.
+//
+// Chunks still honor maxSize, but frames shorter than minSize produce chunks
+// shorter than minSize. Chunk.Hash of a chunk cut at a frame is the fingerprint
+// of its last byte, as for the final chunk.
+func NewFrameAwareChunker(r io.Reader, frameMagic []byte, opts ...Option) (*Chunker, error) {
+	if r == nil {
+		return nil, ErrNilReader
+	}
+
+	if len(frameMagic) == 0 {
+		return nil, ErrEmptyFrameMagic
+	}
+
+	opts = append(slices.Clip(opts), func(c *config) error {
+		c.frameMagic = bytes.Clone(frameMagic)
+
+		return nil
+	})
+
+	return NewChunker(r, opts...)
+}
+
+// frameCut returns the position in available, the rest of the stream from the
+// start of the current chunk, of the first frame magic after its first byte and
+// before maxSize, or len(available) if there is none.
+//
+// Positions searched before are not searched again: the search stops at
+// maxSize, and the next chunk starts at or before that point.
+func (c *Chunker) frameCut(available []byte) int {
+	magic := c.cfg.frameMagic
+	maxSize := int(c.core.MaxSize())
+
+	if c.frameAt > c.offset {
+		// Found by an earlier search
+		if p := int(c.frameAt - c.offset); p < maxSize { //nolint:gosec // G115
+			return p
+		}
+
+		return len(available)
+	}
+
+	from := 1
+	if c.frameSearched > c.offset {
+		from = int(c.frameSearched - c.offset) //nolint:gosec // G115
+	}
+
+	end := min(len(available), maxSize-1+len(magic))
+	if from+len(magic) > end {
+		return len(available)
+	}
+
+	if i := bytes.Index(available[from:end], magic); i >= 0 {
+		c.frameAt = c.offset + uint64(from+i) //nolint:gosec // G115
+
+		return from + i
+	}
+
+	c.frameSearched = c.offset + uint64(end-len(magic)+1) //nolint:gosec // G115
+
+	return len(available)
+}
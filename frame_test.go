@@ -0,0 +1,104 @@
+package fastcdc_test
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestNewFrameAwareChunker(t *testing.T) {
+	t.Parallel()
+
+	magic := []byte{0x28, 0xb5, 0x2f, 0xfd}
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	// Frames shorter than minSize, around the target, longer than maxSize and
+	// ending one byte before maxSize
+	var (
+		stream []byte
+		starts = map[int]bool{}
+	)
+
+	for i, size := range []int{100, 20000, 3000, 150000, 64*1024 - 2, 64*1024 - 5, 50, 40000} {
+		starts[len(stream)] = true
+		stream = append(stream, magic...)
+		stream = append(stream, randBytes(size, int64(i))...)
+	}
+
+	if bytes.Count(stream, magic) != len(starts) {
+		t.Fatal("random frame data contains the magic")
+	}
+
+	inputs := map[string]func([]byte) io.Reader{
+		"reader":      func(b []byte) io.Reader { return bytes.NewReader(b) },
+		"bufio":       func(b []byte) io.Reader { return bufio.NewReaderSize(bytes.NewReader(b), 512*1024) },
+		"short reads": func(b []byte) io.Reader { return iotest.OneByteReader(bytes.NewReader(b)) },
+	}
+
+	chunkSet := func(input func([]byte) io.Reader, data []byte) map[string]bool {
+		c, err := fastcdc.NewFrameAwareChunker(input(data), magic, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		set := map[string]bool{}
+		boundaries := map[int]bool{}
+
+		var out []byte
+
+		err = c.Process(func(chunk fastcdc.Chunk) error {
+			if chunk.Length > 64*1024 {
+				t.Errorf("chunk of %d bytes exceeds maxSize", chunk.Length)
+			}
+
+			boundaries[int(chunk.Offset)] = true
+			set[string(chunk.Data)] = true
+			out = append(out, chunk.Data...)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !bytes.Equal(out, data) {
+			t.Error("chunks do not reproduce the input")
+		}
+
+		for i := range data {
+			if bytes.HasPrefix(data[i:], magic) && !boundaries[i] {
+				t.Errorf("frame at %d does not start a chunk", i)
+			}
+		}
+
+		return set
+	}
+
+	for name, input := range inputs {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			want := chunkSet(input, stream)
+
+			// A new leading frame shifts the others, which keep their chunks
+			shifted := append(append(slices.Clone(magic), randBytes(777, 99)...), stream...)
+			got := chunkSet(input, shifted)
+
+			for chunk := range want {
+				if !got[chunk] {
+					t.Errorf("chunk of %d bytes lost after shifting the frames", len(chunk))
+				}
+			}
+		})
+	}
+
+	if _, err := fastcdc.NewFrameAwareChunker(bytes.NewReader(stream), nil); !errors.Is(err, fastcdc.ErrEmptyFrameMagic) {
+		t.Errorf("expected ErrEmptyFrameMagic, got %v", err)
+	}
+}
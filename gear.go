@@ -0,0 +1,15 @@
+package fastcdc
+
+// gearScanGeneric hashes data starting from fp and returns the position after
+// the first byte where fp & mask == match, the fingerprint there, and whether a
+// match was found. Without a match it returns len(data) and the final fingerprint.
+func gearScanGeneric(table *[256]uint64, data []byte, fp, mask, match uint64) (int, uint64, bool) {
+	for i, b := range data {
+		fp = (fp << 1) + table[b]
+		if fp&mask == match {
+			return i + 1, fp, true
+		}
+	}
+
+	return len(data), fp, false
+}
@@ -0,0 +1,83 @@
+//go:build !purego
+
+package fastcdc
+
+// Long regions are hashed in parallel lanes.
+//
+// The Gear fingerprint is a serial dependency chain, fp = (fp << 1) + table[b],
+// so a single scan is bound by its latency and cannot be vectorized. However,
+// every byte's contribution is shifted out after 64 steps: the fingerprint at any
+// position only depends on the 64 bytes before it. A region can therefore be cut
+// into adjacent blocks hashed in lockstep, each block after the first warming up
+// on the 64 bytes preceding it, which reproduces the sequential fingerprints
+// exactly. The lanes' dependency chains are independent, so the CPU executes them
+// in parallel. The first match is the first one of the lowest lane, so boundaries
+// are identical to the scalar scan. Blocks are hashed in batches so that little
+// work is wasted past the first match.
+//
+// The lanes are kept in general-purpose registers rather than vector registers:
+// each step needs one table lookup per lane, and gather instructions are slower
+// than scalar loads for that. Build with the purego tag to disable the assembly.
+const (
+	// gearLanes is the number of lanes hashed in lockstep.
+	gearLanes = 4
+
+	// gearLaneBlock is the number of bytes each lane hashes per batch. Smaller
+	// blocks waste less work past a match, larger ones amortize the warm-up.
+	gearLaneBlock = 1024
+
+	// gearScanMin is the smallest region scanned with lanes.
+	gearScanMin = gearLanes * gearLaneBlock
+)
+
+// gearLaneState holds the lane fingerprints and the first match of each lane.
+// Its layout is used by gearScanLanes4.
+type gearLaneState struct {
+	fp    [gearLanes]uint64
+	hit   [gearLanes]int // Index of the first match in each lane, -1 if none
+	hitFP [gearLanes]uint64
+}
+
+// gearScanLanes4 hashes the four lanes of q bytes starting at p in lockstep from
+// the fingerprints in s, recording the first match of lanes 1 to 3 in s. It
+// returns the index of the first match in lane 0, or q if there is none, and
+// stores the lane fingerprints at that point in s.
+//
+//go:noescape
+func gearScanLanes4(table *[256]uint64, p *byte, q int, s *gearLaneState, mask, match uint64) int
+
+// gearScan is gearScanGeneric using lanes hashed in assembly.
+func gearScan(table *[256]uint64, data []byte, fp, mask, match uint64) (int, uint64, bool) {
+	const batch = gearLanes * gearLaneBlock
+
+	off := 0
+
+	for ; off+batch <= len(data); off += batch {
+		s := gearLaneState{hit: [gearLanes]int{-1, -1, -1, -1}}
+		s.fp[0] = fp
+
+		// Lanes after the first warm up on the 64 bytes before them
+		for k := 1; k < gearLanes; k++ {
+			start := off + k*gearLaneBlock
+			for _, b := range data[start-64 : start] {
+				s.fp[k] = (s.fp[k] << 1) + table[b]
+			}
+		}
+
+		if i := gearScanLanes4(table, &data[off], gearLaneBlock, &s, mask, match); i < gearLaneBlock {
+			return off + i + 1, s.fp[0], true
+		}
+
+		for k := 1; k < gearLanes; k++ {
+			if s.hit[k] >= 0 {
+				return off + k*gearLaneBlock + s.hit[k] + 1, s.hitFP[k], true
+			}
+		}
+
+		fp = s.fp[gearLanes-1]
+	}
+
+	n, fp, found := gearScanGeneric(table, data[off:], fp, mask, match)
+
+	return off + n, fp, found
+}
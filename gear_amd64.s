@@ -0,0 +1,93 @@
+//go:build !purego
+
+#include "textflag.h"
+
+// func gearScanLanes4(table *[256]uint64, p *byte, q int, s *gearLaneState, mask, match uint64) int
+//
+// Registers: DI table, SI/BX/R12/R13 lane 0-3 data, DX q, CX index,
+// R8-R11 lane 0-3 fingerprints, AX scratch.
+TEXT ·gearScanLanes4(SB), NOSPLIT, $0-56
+	MOVQ table+0(FP), DI
+	MOVQ p+8(FP), SI
+	MOVQ q+16(FP), DX
+	MOVQ s+24(FP), AX
+	MOVQ 0(AX), R8
+	MOVQ 8(AX), R9
+	MOVQ 16(AX), R10
+	MOVQ 24(AX), R11
+	LEAQ (SI)(DX*1), BX
+	LEAQ (BX)(DX*1), R12
+	LEAQ (R12)(DX*1), R13
+	XORQ CX, CX
+	TESTQ DX, DX
+	JEQ done
+
+loop:
+	MOVBQZX (SI)(CX*1), AX
+	SHLQ $1, R8
+	ADDQ (DI)(AX*8), R8
+	MOVBQZX (BX)(CX*1), AX
+	SHLQ $1, R9
+	ADDQ (DI)(AX*8), R9
+	MOVBQZX (R12)(CX*1), AX
+	SHLQ $1, R10
+	ADDQ (DI)(AX*8), R10
+	MOVBQZX (R13)(CX*1), AX
+	SHLQ $1, R11
+	ADDQ (DI)(AX*8), R11
+
+	MOVQ R8, AX
+	ANDQ mask+32(FP), AX
+	CMPQ AX, match+40(FP)
+	JEQ done
+	MOVQ R9, AX
+	ANDQ mask+32(FP), AX
+	CMPQ AX, match+40(FP)
+	JEQ hit1
+	MOVQ R10, AX
+	ANDQ mask+32(FP), AX
+	CMPQ AX, match+40(FP)
+	JEQ hit2
+	MOVQ R11, AX
+	ANDQ mask+32(FP), AX
+	CMPQ AX, match+40(FP)
+	JEQ hit3
+
+next:
+	INCQ CX
+	CMPQ CX, DX
+	JB loop
+
+done:
+	MOVQ s+24(FP), AX
+	MOVQ R8, 0(AX)
+	MOVQ R9, 8(AX)
+	MOVQ R10, 16(AX)
+	MOVQ R11, 24(AX)
+	MOVQ CX, ret+48(FP)
+	RET
+
+	// Record only the first match of lanes 1 to 3: s.hit[k] at 32+8k, s.hitFP[k] at 64+8k
+hit1:
+	MOVQ s+24(FP), AX
+	CMPQ 40(AX), $-1
+	JNE next
+	MOVQ CX, 40(AX)
+	MOVQ R9, 72(AX)
+	JMP next
+
+hit2:
+	MOVQ s+24(FP), AX
+	CMPQ 48(AX), $-1
+	JNE next
+	MOVQ CX, 48(AX)
+	MOVQ R10, 80(AX)
+	JMP next
+
+hit3:
+	MOVQ s+24(FP), AX
+	CMPQ 56(AX), $-1
+	JNE next
+	MOVQ CX, 56(AX)
+	MOVQ R11, 88(AX)
+	JMP next
@@ -0,0 +1,14 @@
+//go:build !amd64 || purego
+
+package fastcdc
+
+import "math"
+
+// gearScanMin disables lane scans: without assembly the unrolled scalar loops
+// of FindBoundary are faster.
+const gearScanMin = math.MaxInt
+
+// gearScan is gearScanGeneric on platforms without a lane implementation.
+func gearScan(table *[256]uint64, data []byte, fp, mask, match uint64) (int, uint64, bool) {
+	return gearScanGeneric(table, data, fp, mask, match)
+}
@@ -0,0 +1,182 @@
+package fastcdc_test
+
+import (
+	"math/rand"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// cut is a chunk boundary and the fingerprint there.
+type cut struct {
+	end  int
+	hash uint64
+}
+
+// referenceBoundaries chunks data one byte at a time following the FastCDC rules,
+// as an independent reference for the optimized (unrolled and lane) scans. With
+// fromStart, every byte of a chunk is hashed rather than those past minSize.
+func referenceBoundaries(table *[256]uint64, data []byte, minSize, normSize, maxSize int, maskS, maskL uint64,
+	fromStart bool,
+) []cut {
+	var (
+		cuts  []cut
+		fp    uint64
+		start int
+	)
+
+	for i, b := range data {
+		size := i - start + 1
+		if fromStart {
+			fp = (fp << 1) + table[b]
+		}
+
+		if size <= minSize {
+			continue
+		}
+
+		if !fromStart {
+			fp = (fp << 1) + table[b]
+		}
+
+		mask := maskL
+		if size <= normSize {
+			mask = maskS
+		}
+
+		if fp&mask == 0 || size >= maxSize {
+			cuts = append(cuts, cut{i + 1, fp})
+			fp, start = 0, i+1
+		}
+	}
+
+	return cuts
+}
+
+// TestFindBoundaryMatchesReference checks that FindBoundary produces the same
+// boundaries as a byte-by-byte reference scan, across sizes long enough for the
+// lane scans and with a random table.
+func TestFindBoundaryMatchesReference(t *testing.T) {
+	t.Parallel()
+
+	rng := rand.New(rand.NewSource(1281)) //nolint:gosec // deterministic test data
+
+	var table [256]uint64
+	for i := range table {
+		table[i] = rng.Uint64()
+	}
+
+	data := randBytes(8*1024*1024, 1281)
+
+	for _, tt := range []struct {
+		minSize, targetSize, maxSize uint32
+	}{
+		{2 * 1024, 8 * 1024, 64 * 1024},
+		{16 * 1024, 64 * 1024, 256 * 1024},
+		{64 * 1024, 256 * 1024, 4 * 1024 * 1024},
+		{1024, 1024 * 1024, 8 * 1024 * 1024},
+	} {
+		core, err := fastcdc.NewChunkerCore(
+			fastcdc.WithTable(table),
+			fastcdc.WithMinSize(tt.minSize),
+			fastcdc.WithTargetSize(tt.targetSize),
+			fastcdc.WithMaxSize(tt.maxSize),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bits := 0
+		for size := tt.targetSize; size > 1; size >>= 1 {
+			bits++
+		}
+
+		want := referenceBoundaries(&table, data, int(tt.minSize), int(core.NormSize()), int(tt.maxSize),
+			1<<(bits-1)-1, 1<<bits-1, false)
+
+		var got []cut
+
+		core.FindAllBoundaries(data, func(offset, length int, hash uint64) {
+			got = append(got, cut{offset + length, hash})
+		})
+
+		if !slices.Equal(got, want) {
+			t.Errorf("Target %d: FindBoundary found %d boundaries, reference %d", tt.targetSize, len(got), len(want))
+		}
+	}
+}
+
+// TestWithHashFromStart checks that WithHashFromStart matches a reference that
+// hashes every byte, with each Gear loop and across calls.
+func TestWithHashFromStart(t *testing.T) {
+	t.Parallel()
+
+	table := fastcdc.GenerateTable(1324)
+	data := randBytes(4*1024*1024, 1324)
+
+	for _, tt := range []struct {
+		minSize, targetSize, maxSize uint32
+		differs                      bool // Boundaries differ from the default ones
+	}{
+		{32, 256, 1024, true}, // minSize below the Gear window
+		{2 * 1024, 8 * 1024, 64 * 1024, true},
+		{16 * 1024, 64 * 1024, 256 * 1024, false}, // Too few chunks for a match in the window
+	} {
+		opts := []fastcdc.Option{
+			fastcdc.WithTable(table),
+			fastcdc.WithMinSize(tt.minSize),
+			fastcdc.WithTargetSize(tt.targetSize),
+			fastcdc.WithMaxSize(tt.maxSize),
+			fastcdc.WithHashFromStart(),
+		}
+
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		bits := 0
+		for size := tt.targetSize; size > 1; size >>= 1 {
+			bits++
+		}
+
+		want := referenceBoundaries(&table, data, int(tt.minSize), int(core.NormSize()), int(tt.maxSize),
+			1<<(bits-1)-1, 1<<bits-1, true)
+
+		if tt.differs == slices.Equal(want, referenceBoundaries(&table, data, int(tt.minSize), int(core.NormSize()),
+			int(tt.maxSize), 1<<(bits-1)-1, 1<<bits-1, false)) {
+			t.Fatalf("Target %d: hashing from the start does not change the boundaries as expected", tt.targetSize)
+		}
+
+		for _, unroll := range []bool{true, false} {
+			core, err := fastcdc.NewChunkerCore(append(opts, fastcdc.WithUnroll(unroll))...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// Blocks of 100 bytes make chunks span calls, before and after minSize
+			var (
+				got    []cut
+				offset int
+			)
+
+			for block := range slices.Chunk(data, 100) {
+				for len(block) > 0 {
+					boundary, hash, found := core.FindBoundary(block)
+					offset += boundary
+					block = block[boundary:]
+
+					if found {
+						got = append(got, cut{offset, hash})
+						core.Reset()
+					}
+				}
+			}
+
+			if !slices.Equal(got, want) {
+				t.Errorf("Target %d, unroll %v: found %d boundaries, reference %d", tt.targetSize, unroll, len(got), len(want))
+			}
+		}
+	}
+}
@@ -0,0 +1,145 @@
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+)
+
+// ErrGoldenMismatch is returned by CheckGoldenVectors when boundaries differ from a golden vector.
+var ErrGoldenMismatch = errors.New("chunk boundaries differ from golden vector")
+
+// GoldenBoundaries returns the end offset of every chunk of data, including the
+// final partial chunk, for storing in golden files. Content-addressed stores can
+// commit them for representative inputs and compare after each library upgrade,
+// since any boundary change rewrites the hashes of all affected chunks.
+func GoldenBoundaries(data []byte, opts ...Option) ([]int, error) {
+	core, err := NewChunkerCore(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var boundaries []int
+
+	tail := core.FindAllBoundaries(data, func(offset, length int, _ uint64) {
+		boundaries = append(boundaries, offset+length)
+	})
+
+	if tail > 0 {
+		boundaries = append(boundaries, len(data))
+	}
+
+	return boundaries, nil
+}
+
+// SameBoundaries reports whether the streaming API cuts data at exactly the same
+// offsets with a and b, such as before rolling out a change of normalization or
+// seed to a content-addressed store, where any moved boundary rewrites the
+// hashes of the affected chunks. Both configs must come from NewConfig. Data
+// rejected by both configs at the same chunk, for example with TailError,
+// counts as the same.
+//
+// The result only holds for data: configs that agree on a sample may still
+// differ on other inputs, so pass representative data.
+func SameBoundaries(a, b Config, data []byte) bool {
+	ca, cb := boundaryChunker(&a, data), boundaryChunker(&b, data)
+
+	for {
+		x, errA := ca.Next()
+		y, errB := cb.Next()
+
+		if errA != nil || errB != nil {
+			return errA != nil && errB != nil && errors.Is(errA, io.EOF) == errors.Is(errB, io.EOF)
+		}
+
+		// Chunks before these matched, so they start at the same offset
+		if x.Length != y.Length {
+			return false
+		}
+	}
+}
+
+// boundaryChunker returns a Chunker over data with cfg that skips the per-chunk
+// work that does not affect boundaries.
+func boundaryChunker(cfg *Config, data []byte) *Chunker {
+	c := newChunkerFromConfig(cfg)
+	c.digest, c.idHash = nil, nil
+	c.cfg.copyData, c.cfg.wide, c.cfg.progress = false, false, nil
+	c.cfg.tee = nil
+	c.buf = data
+	c.external = true
+	c.eof = true
+
+	return c
+}
+
+// GoldenVector is a known input and configuration with the boundaries this
+// library produces for it. The vectors returned by GoldenVectors are frozen
+// together with the algorithm versions they use.
+type GoldenVector struct {
+	Name       string
+	InputSeed  uint64 // Seed of the generated input, see TestData
+	InputSize  int    // Size of the generated input in bytes
+	MinSize    uint32
+	TargetSize uint32
+	MaxSize    uint32
+	NormLevel  uint8
+	TableSeed  uint64 // Seed passed to WithSeed
+	Boundaries []int  // Expected GoldenBoundaries
+}
+
+// Input returns the vector's input, TestData(InputSeed, InputSize).
+func (v GoldenVector) Input() []byte {
+	return TestData(v.InputSeed, v.InputSize)
+}
+
+// Options returns the options the vector was chunked with.
+func (v GoldenVector) Options() []Option {
+	return []Option{
+		WithAlgorithmVersion(AlgorithmV1),
+		WithMinSize(v.MinSize),
+		WithTargetSize(v.TargetSize),
+		WithMaxSize(v.MaxSize),
+		WithNormalization(v.NormLevel),
+		WithSeed(v.TableSeed),
+	}
+}
+
+// GoldenVectors returns the committed golden vectors.
+func GoldenVectors() []GoldenVector {
+	return slices.Clone(goldenVectors)
+}
+
+// CheckGoldenVectors chunks every golden vector and returns an error wrapping
+// ErrGoldenMismatch for the first one whose boundaries differ. Forks and
+// downstream users can call it from their tests to pin chunking stability.
+func CheckGoldenVectors() error {
+	for _, v := range goldenVectors {
+		got, err := GoldenBoundaries(v.Input(), v.Options()...)
+		if err != nil {
+			return fmt.Errorf("golden vector %s: %w", v.Name, err)
+		}
+
+		if i := firstDifference(got, v.Boundaries); i >= 0 {
+			return fmt.Errorf("%w: %s: boundary %d of %d", ErrGoldenMismatch, v.Name, i, len(v.Boundaries))
+		}
+	}
+
+	return nil
+}
+
+// firstDifference returns the index of the first differing element of a and b, or -1.
+func firstDifference(a, b []int) int {
+	for i := range min(len(a), len(b)) {
+		if a[i] != b[i] {
+			return i
+		}
+	}
+
+	if len(a) != len(b) {
+		return min(len(a), len(b))
+	}
+
+	return -1
+}
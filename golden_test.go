@@ -0,0 +1,154 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestCheckGoldenVectors(t *testing.T) {
+	t.Parallel()
+
+	if err := fastcdc.CheckGoldenVectors(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGoldenBoundariesMatchChunker(t *testing.T) {
+	t.Parallel()
+
+	for _, v := range fastcdc.GoldenVectors() {
+		t.Run(v.Name, func(t *testing.T) {
+			t.Parallel()
+
+			data := v.Input()
+
+			chunker, err := fastcdc.NewChunkerFromBytes(data, v.Options()...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []int
+
+			for {
+				chunk, err := chunker.Next()
+				if errors.Is(err, io.EOF) {
+					break
+				}
+
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				want = append(want, int(chunk.Offset)+int(chunk.Length)) //nolint:gosec // G115
+			}
+
+			if !slices.Equal(v.Boundaries, want) {
+				t.Errorf("golden boundaries %v, chunker boundaries %v", v.Boundaries, want)
+			}
+		})
+	}
+}
+
+func TestGoldenBoundaries(t *testing.T) {
+	t.Parallel()
+
+	boundaries, err := fastcdc.GoldenBoundaries(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(boundaries) != 0 {
+		t.Errorf("empty input: got %v, want no boundaries", boundaries)
+	}
+
+	boundaries, err = fastcdc.GoldenBoundaries(make([]byte, 100))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(boundaries, []int{100}) {
+		t.Errorf("short input: got %v, want [100]", boundaries)
+	}
+
+	if _, err := fastcdc.GoldenBoundaries(nil, fastcdc.WithTargetSize(0)); !errors.Is(err, fastcdc.ErrInvalidTargetSize) {
+		t.Errorf("expected ErrInvalidTargetSize, got %v", err)
+	}
+}
+
+func TestGoldenVectorsAreCopies(t *testing.T) {
+	t.Parallel()
+
+	vectors := fastcdc.GoldenVectors()
+	vectors[0].Boundaries = nil
+
+	if len(fastcdc.GoldenVectors()[0].Boundaries) == 0 {
+		t.Error("modifying the returned vectors changed the package vectors")
+	}
+}
+
+func TestSameBoundaries(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024, 1316)
+
+	config := func(opts ...fastcdc.Option) fastcdc.Config {
+		t.Helper()
+
+		cfg, err := fastcdc.NewConfig(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return cfg
+	}
+
+	base := config()
+
+	tests := []struct {
+		name string
+		cfg  fastcdc.Config
+		want bool
+	}{
+		{"identical", config(), true},
+		{"per-chunk options only", config(fastcdc.WithChunkDigest(sha256.New), fastcdc.WithCopyData(),
+			fastcdc.WithBufferSize(4*1024*1024)), true},
+		{"failing tee", config(fastcdc.WithTee(&limitWriter{err: errTestRead})), true},
+		{"explicit default seed", config(fastcdc.WithSeed(0)), true},
+		{"seed", config(fastcdc.WithSeed(1)), false},
+		{"normalization", config(fastcdc.WithNormalization(1)), false},
+		{"target size", config(fastcdc.WithTargetSize(32 * 1024)), false},
+	}
+
+	for _, tt := range tests {
+		if got := fastcdc.SameBoundaries(base, tt.cfg, data); got != tt.want {
+			t.Errorf("%s: SameBoundaries = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// The comparison input is not written to a tee
+	var tee bytes.Buffer
+	if !fastcdc.SameBoundaries(base, config(fastcdc.WithTee(&tee)), data) || tee.Len() != 0 {
+		t.Errorf("SameBoundaries wrote %d bytes to the tee", tee.Len())
+	}
+
+	// Short data can agree where longer data does not
+	if !fastcdc.SameBoundaries(base, config(fastcdc.WithSeed(1)), data[:1000]) {
+		t.Error("configs disagree on data shorter than minSize")
+	}
+
+	// Rejected by both
+	tailError := config(fastcdc.WithTailPolicy(fastcdc.TailError))
+	if !fastcdc.SameBoundaries(tailError, tailError, data[:1000]) {
+		t.Error("configs rejecting the same tail disagree")
+	}
+
+	if fastcdc.SameBoundaries(base, tailError, data[:1000]) {
+		t.Error("config rejecting the tail agrees with one emitting it")
+	}
+}
@@ -0,0 +1,69 @@
+package fastcdc
+
+// goldenVectors pins the boundaries of AlgorithmV1. Never change existing
+// vectors; a boundary change requires a new algorithm version.
+//
+//nolint:gochecknoglobals
+var goldenVectors = []GoldenVector{
+	{
+		Name: "default", InputSeed: 1, InputSize: 1048576,
+		MinSize: 16384, TargetSize: 65536, MaxSize: 262144, NormLevel: 2, TableSeed: 0x0,
+		Boundaries: []int{
+			65237, 100057, 314141, 399060, 455677, 475110, 564118, 591173,
+			810339, 885093, 905793, 996003, 1048576,
+		},
+	},
+	{
+		Name: "small", InputSeed: 2, InputSize: 262144,
+		MinSize: 512, TargetSize: 2048, MaxSize: 8192, NormLevel: 2, TableSeed: 0x0,
+		Boundaries: []int{
+			1383, 6603, 7860, 8545, 9387, 10505, 11157, 15783,
+			16702, 17327, 22545, 25034, 28676, 32612, 33275, 37014,
+			38468, 40229, 41960, 42535, 43377, 44111, 45716, 46754,
+			48366, 51883, 53238, 55568, 59024, 60589, 62574, 66962,
+			68954, 69834, 70706, 73931, 74611, 80833, 81897, 83675,
+			86398, 87206, 87792, 89013, 97205, 99489, 100275, 100999,
+			101816, 104409, 106604, 107620, 112140, 113718, 116152, 121647,
+			122845, 123668, 124531, 126306, 130831, 131388, 134177, 140819,
+			148845, 150584, 155818, 157439, 160892, 161721, 164528, 165109,
+			168871, 169524, 170279, 170918, 173301, 175177, 179049, 180900,
+			184158, 185062, 186193, 186869, 190371, 193682, 194202, 195002,
+			195777, 203969, 208237, 212478, 213135, 215137, 220555, 222286,
+			223129, 224080, 225673, 227707, 235899, 236473, 238838, 239507,
+			240665, 242147, 242964, 243890, 246555, 247306, 247944, 248504,
+			249635, 252135, 254414, 254992, 259304, 261151, 262144,
+		},
+	},
+	{
+		Name: "no-normalization", InputSeed: 3, InputSize: 1048576,
+		MinSize: 16384, TargetSize: 65536, MaxSize: 262144, NormLevel: 0, TableSeed: 0x0,
+		Boundaries: []int{
+			31261, 58671, 78419, 98192, 119521, 156594, 185278, 215033,
+			308300, 340319, 415795, 519943, 538155, 568796, 588689, 644832,
+			769791, 929692, 966858, 1048576,
+		},
+	},
+	{
+		Name: "seeded-table", InputSeed: 4, InputSize: 1048576,
+		MinSize: 16384, TargetSize: 65536, MaxSize: 262144, NormLevel: 2, TableSeed: 0xdeadbeef,
+		Boundaries: []int{
+			53993, 108185, 173154, 305839, 324246, 382185, 410697, 504856,
+			525258, 557874, 657198, 704508, 835678, 989663, 1048576,
+		},
+	},
+	{
+		Name: "large", InputSeed: 5, InputSize: 4194304,
+		MinSize: 65536, TargetSize: 262144, MaxSize: 1048576, NormLevel: 3, TableSeed: 0x0,
+		Boundaries: []int{
+			757992, 1036729, 1773231, 1927233, 2374716, 2986125, 3066662, 3390224,
+			3566989, 4194304,
+		},
+	},
+	{
+		Name: "tail", InputSeed: 6, InputSize: 100000,
+		MinSize: 4096, TargetSize: 16384, MaxSize: 65536, NormLevel: 1, TableSeed: 0x0,
+		Boundaries: []int{
+			10643, 20567, 42955, 52393, 93528, 100000,
+		},
+	},
+}
@@ -0,0 +1,93 @@
+package fastcdc
+
+import (
+	"crypto/sha256"
+	"hash"
+)
+
+// HashTree is a binary hash tree (Merkle tree) over the chunks of a stream.
+type HashTree struct {
+	Root   []byte   // Digest of the whole tree
+	Leaves [][]byte // Digest of each chunk, in stream order
+}
+
+// BuildHashTree consumes the rest of the stream, hashes each chunk with a hash
+// from leaf and combines the digests pairwise with a hash from node, level by
+// level, up to a single root. A node digest is node(left || right); an odd
+// digest at the end of a level is carried up unchanged. The root of a single
+// chunk is its leaf digest, and that of an empty stream is the leaf digest of
+// no data. Nil hash constructors default to SHA-256.
+//
+// The leaf digests are returned with the root, so a peer holding the root can
+// verify chunks individually once the leaves check out against it with
+// HashTreeRoot. Use hashes with distinct leaf and node prefixes if trees
+// from untrusted sources must not collide across levels. Read errors are
+// returned with a zero HashTree.
+func (c *Chunker) BuildHashTree(leaf, node func() hash.Hash) (HashTree, error) {
+	if leaf == nil {
+		leaf = sha256.New
+	}
+
+	h := leaf()
+
+	var leaves [][]byte
+
+	err := c.Process(func(chunk Chunk) error {
+		data := chunk.Data
+		if c.cfg.guardedData {
+			data = chunk.Guarded.Bytes()
+		}
+
+		h.Reset()
+		h.Write(data)
+		leaves = append(leaves, h.Sum(nil))
+
+		return nil
+	})
+	if err != nil {
+		return HashTree{}, err
+	}
+
+	if len(leaves) == 0 {
+		h.Reset()
+
+		return HashTree{Root: h.Sum(nil)}, nil
+	}
+
+	return HashTree{Root: HashTreeRoot(leaves, node), Leaves: leaves}, nil
+}
+
+// HashTreeRoot combines leaf digests into the root of a HashTree as
+// BuildHashTree does, hashing pairs with a hash from node (SHA-256 if nil).
+// It returns nil if there are no leaves. The leaves are not modified.
+func HashTreeRoot(leaves [][]byte, node func() hash.Hash) []byte {
+	if len(leaves) == 0 {
+		return nil
+	}
+
+	if node == nil {
+		node = sha256.New
+	}
+
+	h := node()
+	level := leaves
+
+	for len(level) > 1 {
+		next := make([][]byte, 0, (len(level)+1)/2)
+
+		for i := 0; i+1 < len(level); i += 2 {
+			h.Reset()
+			h.Write(level[i])
+			h.Write(level[i+1])
+			next = append(next, h.Sum(nil))
+		}
+
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+
+		level = next
+	}
+
+	return level[0]
+}
@@ -0,0 +1,126 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestBuildHashTree(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1301)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	var chunks [][]byte
+
+	err := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithCopyData())...).Process(func(chunk fastcdc.Chunk) error {
+		chunks = append(chunks, chunk.Data)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tree, err := mustChunker(t, bytes.NewReader(data), opts...).BuildHashTree(sha256.New, sha512.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tree.Leaves) != len(chunks) || len(chunks) < 3 {
+		t.Fatalf("got %d leaves for %d chunks", len(tree.Leaves), len(chunks))
+	}
+
+	// Reference tree: hash every level by hand, carrying odd digests up
+	level := make([][]byte, len(chunks))
+	for i, chunk := range chunks {
+		sum := sha256.Sum256(chunk)
+		level[i] = sum[:]
+
+		if !bytes.Equal(tree.Leaves[i], level[i]) {
+			t.Errorf("leaf %d does not match the chunk digest", i)
+		}
+	}
+
+	for len(level) > 1 {
+		var next [][]byte
+
+		for i := 0; i < len(level); i += 2 {
+			if i+1 == len(level) {
+				next = append(next, level[i])
+
+				continue
+			}
+
+			sum := sha512.Sum512(append(bytes.Clone(level[i]), level[i+1]...))
+			next = append(next, sum[:])
+		}
+
+		level = next
+	}
+
+	if !bytes.Equal(tree.Root, level[0]) {
+		t.Errorf("root = %x, want %x", tree.Root, level[0])
+	}
+
+	if root := fastcdc.HashTreeRoot(tree.Leaves, sha512.New); !bytes.Equal(root, tree.Root) {
+		t.Errorf("HashTreeRoot = %x, want %x", root, tree.Root)
+	}
+
+	// The tree only depends on the chunk data
+	guarded, err := mustChunker(t, iotest.HalfReader(bytes.NewReader(data)), append(opts, fastcdc.WithGuardedData(true))...).BuildHashTree(sha256.New, sha512.New)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(guarded.Root, tree.Root) {
+		t.Error("root differs with guarded data and short reads")
+	}
+}
+
+func TestBuildHashTreeSmall(t *testing.T) {
+	t.Parallel()
+
+	empty, err := mustChunker(t, bytes.NewReader(nil)).BuildHashTree(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum := sha256.Sum256(nil); !bytes.Equal(empty.Root, sum[:]) || len(empty.Leaves) != 0 {
+		t.Errorf("empty stream: root %x with %d leaves, want %x and none", empty.Root, len(empty.Leaves), sum)
+	}
+
+	single, err := mustChunker(t, bytes.NewReader([]byte("one chunk"))).BuildHashTree(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if sum := sha256.Sum256([]byte("one chunk")); !bytes.Equal(single.Root, sum[:]) || len(single.Leaves) != 1 {
+		t.Errorf("single chunk: root %x with %d leaves, want the leaf digest %x", single.Root, len(single.Leaves), sum)
+	}
+
+	if root := fastcdc.HashTreeRoot(nil, nil); root != nil {
+		t.Errorf("HashTreeRoot(nil) = %x, want nil", root)
+	}
+}
+
+func TestBuildHashTreeReadError(t *testing.T) {
+	t.Parallel()
+
+	errRead := errors.New("read failed")
+
+	tree, err := mustChunker(t, iotest.ErrReader(errRead)).BuildHashTree(nil, nil)
+	if !errors.Is(err, errRead) {
+		t.Errorf("got %v, want the read error", err)
+	}
+
+	if tree.Root != nil || tree.Leaves != nil {
+		t.Error("expected a zero HashTree on error")
+	}
+}
@@ -0,0 +1,169 @@
+package fastcdc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+var (
+	// ErrChunkDigestMismatch is returned when a stored chunk does not match its manifest digest.
+	ErrChunkDigestMismatch = errors.New("chunk digest mismatch")
+
+	// ErrChunkLengthMismatch is returned when a stored chunk does not match its manifest length.
+	ErrChunkLengthMismatch = errors.New("chunk length mismatch")
+
+	// ErrManifestLengthMismatch is returned when the reconstructed stream length
+	// does not match the manifest total length.
+	ErrManifestLengthMismatch = errors.New("manifest length mismatch")
+)
+
+// Manifest describes how to reconstruct a stream from content-addressed chunks.
+type Manifest struct {
+	Length  uint64          // Total stream length in bytes
+	Entries []ManifestEntry // Chunks in stream order
+}
+
+// ManifestEntry describes a single chunk of a Manifest.
+type ManifestEntry struct {
+	Offset uint64 // Absolute offset in the stream
+	Length uint32 // Chunk size in bytes
+	Digest []byte // Content digest of the chunk data, also used as the store key
+}
+
+// ChunkRef locates a chunk in a stream without holding its data.
+//
+// It marshals to JSON as an object with the fields "offset", "length" and
+// "hash", the hash as 16 lowercase hex digits, so persisted manifests are
+// stable and human-readable.
+type ChunkRef struct {
+	Offset uint64 // Absolute offset in the stream
+	Length uint32 // Chunk size in bytes
+	Hash   uint64 // Gear fingerprint at boundary
+}
+
+// chunkRefJSON is the JSON form of a ChunkRef.
+type chunkRefJSON struct {
+	Offset uint64 `json:"offset"`
+	Length uint32 `json:"length"`
+	Hash   string `json:"hash"`
+}
+
+// Ref returns the location and fingerprint of the chunk, without its data.
+func (c Chunk) Ref() ChunkRef {
+	return ChunkRef{Offset: c.Offset, Length: c.Length, Hash: c.Hash}
+}
+
+// MarshalJSON implements json.Marshaler.
+func (r ChunkRef) MarshalJSON() ([]byte, error) {
+	return json.Marshal(chunkRefJSON{Offset: r.Offset, Length: r.Length, Hash: fmt.Sprintf("%016x", r.Hash)})
+}
+
+// UnmarshalJSON implements json.Unmarshaler. The hash must be a hex string of
+// at most 16 digits.
+func (r *ChunkRef) UnmarshalJSON(data []byte) error {
+	var v chunkRefJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+
+	hash, err := strconv.ParseUint(v.Hash, 16, 64)
+	if err != nil {
+		return fmt.Errorf("chunk at offset %d: invalid hash %q: %w", v.Offset, v.Hash, err)
+	}
+
+	*r = ChunkRef{Offset: v.Offset, Length: v.Length, Hash: hash}
+
+	return nil
+}
+
+// Manifest consumes the rest of the stream and returns the location and Gear
+// fingerprint of every chunk, in stream order. No chunk data is copied or retained.
+// Read errors are returned together with the refs collected so far.
+func (c *Chunker) Manifest() ([]ChunkRef, error) {
+	var refs []ChunkRef
+
+	err := c.Process(func(chunk Chunk) error {
+		refs = append(refs, chunk.Ref())
+
+		return nil
+	})
+
+	return refs, err
+}
+
+// ChunkStore retrieves chunk data by content digest.
+type ChunkStore interface {
+	Get(digest []byte) ([]byte, error)
+}
+
+// ReconstructVerified writes the stream described by m to w, fetching each chunk
+// from store. Every chunk is verified against its manifest length and digest before
+// it is written, and the total written length is checked against m.Length, so a
+// corrupt or tampered store is caught during the restore.
+//
+// The digest is computed with the function set by WithChunkDigest (SHA-256 by default).
+// Errors name the offset of the failing chunk and wrap ErrChunkDigestMismatch,
+// ErrChunkLengthMismatch or ErrManifestLengthMismatch.
+func ReconstructVerified(m *Manifest, store ChunkStore, w io.Writer, opts ...Option) error {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return err
+		}
+	}
+
+	newHash := cfg.digest
+	if newHash == nil {
+		newHash = sha256.New
+	}
+
+	h := newHash()
+
+	var (
+		written uint64
+		sum     []byte
+	)
+
+	for _, entry := range m.Entries {
+		data, err := store.Get(entry.Digest)
+		if err != nil {
+			return fmt.Errorf("fetching chunk at offset %d: %w", entry.Offset, err)
+		}
+
+		if len(data) != int(entry.Length) {
+			return fmt.Errorf("%w: chunk at offset %d: expected %d bytes, got %d",
+				ErrChunkLengthMismatch, entry.Offset, entry.Length, len(data))
+		}
+
+		h.Reset()
+		h.Write(data)
+		sum = h.Sum(sum[:0])
+
+		if !bytes.Equal(sum, entry.Digest) {
+			return fmt.Errorf("%w: chunk at offset %d: expected %x, got %x",
+				ErrChunkDigestMismatch, entry.Offset, entry.Digest, sum)
+		}
+
+		n, err := w.Write(data)
+		written += uint64(n) //nolint:gosec // G115
+
+		if err == nil && n < len(data) {
+			err = io.ErrShortWrite
+		}
+
+		if err != nil {
+			return fmt.Errorf("writing chunk at offset %d: %w", entry.Offset, err)
+		}
+	}
+
+	if written != m.Length {
+		return fmt.Errorf("%w: expected %d bytes, wrote %d", ErrManifestLengthMismatch, m.Length, written)
+	}
+
+	return nil
+}
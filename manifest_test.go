@@ -0,0 +1,228 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// mapStore is an in-memory ChunkStore keyed by digest.
+type mapStore map[string][]byte
+
+func (s mapStore) Get(digest []byte) ([]byte, error) {
+	data, ok := s[string(digest)]
+	if !ok {
+		return nil, fmt.Errorf("chunk %x: %w", digest, io.ErrUnexpectedEOF)
+	}
+
+	return data, nil
+}
+
+// buildManifest chunks data into a manifest and a store holding each chunk.
+func buildManifest(t *testing.T, data []byte) (*fastcdc.Manifest, mapStore) {
+	t.Helper()
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithTargetSize(16*1024), fastcdc.WithMinSize(4*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m := &fastcdc.Manifest{Length: uint64(len(data))}
+	store := mapStore{}
+
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sum := sha256.Sum256(chunk.Data)
+		store[string(sum[:])] = bytes.Clone(chunk.Data)
+		m.Entries = append(m.Entries, fastcdc.ManifestEntry{
+			Offset: chunk.Offset,
+			Length: chunk.Length,
+			Digest: sum[:],
+		})
+	}
+
+	return m, store
+}
+
+// TestReconstructVerified tests a clean restore and detection of a corrupt chunk.
+func TestReconstructVerified(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 256*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	m, store := buildManifest(t, data)
+	if len(m.Entries) < 3 {
+		t.Fatalf("Expected at least 3 chunks, got %d", len(m.Entries))
+	}
+
+	var out bytes.Buffer
+	if err := fastcdc.ReconstructVerified(m, store, &out, fastcdc.WithChunkDigest(sha256.New)); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.Bytes(), data) {
+		t.Fatal("Reconstructed data does not match input")
+	}
+
+	// Corrupt the third chunk
+	bad := m.Entries[2]
+	store[string(bad.Digest)][0] ^= 0xff
+
+	out.Reset()
+
+	err := fastcdc.ReconstructVerified(m, store, &out)
+	if !errors.Is(err, fastcdc.ErrChunkDigestMismatch) {
+		t.Fatalf("Expected ErrChunkDigestMismatch, got %v", err)
+	}
+
+	if !strings.Contains(err.Error(), fmt.Sprintf("offset %d", bad.Offset)) {
+		t.Errorf("Error does not name the failing offset %d: %v", bad.Offset, err)
+	}
+
+	if uint64(out.Len()) != bad.Offset {
+		t.Errorf("Wrote %d bytes before failing, want %d", out.Len(), bad.Offset)
+	}
+}
+
+// TestReconstructVerifiedLength tests detection of a manifest length mismatch.
+func TestReconstructVerifiedLength(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 64*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	m, store := buildManifest(t, data)
+	m.Length++
+
+	err := fastcdc.ReconstructVerified(m, store, io.Discard)
+	if !errors.Is(err, fastcdc.ErrManifestLengthMismatch) {
+		t.Fatalf("Expected ErrManifestLengthMismatch, got %v", err)
+	}
+}
+
+// TestReconstructVerifiedShortWrite tests that a writer accepting fewer bytes
+// than a chunk without an error stops the restore at that chunk.
+func TestReconstructVerifiedShortWrite(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(256*1024, 1224)
+
+	m, store := buildManifest(t, data)
+	if len(m.Entries) < 2 {
+		t.Fatalf("Need several chunks, got %d", len(m.Entries))
+	}
+
+	w := &limitWriter{n: int(m.Entries[0].Length) + 10}
+
+	err := fastcdc.ReconstructVerified(m, store, w)
+	if !errors.Is(err, io.ErrShortWrite) || !strings.Contains(err.Error(), fmt.Sprint(m.Entries[1].Offset)) {
+		t.Fatalf("Expected io.ErrShortWrite at offset %d, got %v", m.Entries[1].Offset, err)
+	}
+}
+
+func TestChunkerManifest(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1280)
+
+	c, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	refs, err := c.Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	c.Reset(bytes.NewReader(data))
+
+	i := 0
+
+	err = c.Process(func(chunk fastcdc.Chunk) error {
+		want := fastcdc.ChunkRef{Offset: chunk.Offset, Length: chunk.Length, Hash: chunk.Hash}
+		if i >= len(refs) || refs[i] != want {
+			t.Fatalf("Ref %d does not match chunk %+v", i, want)
+		}
+
+		i++
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if i != len(refs) {
+		t.Errorf("Manifest has %d refs, want %d", len(refs), i)
+	}
+
+	// The stream is consumed
+	if refs, err := c.Manifest(); err != nil || len(refs) != 0 {
+		t.Errorf("Manifest of a consumed stream = %v, %v; want empty", refs, err)
+	}
+}
+
+func TestChunkRefJSON(t *testing.T) {
+	t.Parallel()
+
+	refs := []fastcdc.ChunkRef{
+		{Offset: 0, Length: 17000, Hash: 0x1f},
+		{Offset: 17000, Length: 65536, Hash: 0xdeadbeefcafef00d},
+	}
+
+	data, err := json.Marshal(refs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := `[{"offset":0,"length":17000,"hash":"000000000000001f"},` +
+		`{"offset":17000,"length":65536,"hash":"deadbeefcafef00d"}]`
+	if string(data) != want {
+		t.Errorf("got %s, want %s", data, want)
+	}
+
+	var decoded []fastcdc.ChunkRef
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(decoded, refs) {
+		t.Errorf("round trip gave %+v, want %+v", decoded, refs)
+	}
+
+	// A chunk converts to the same JSON without its data
+	chunk := fastcdc.Chunk{Offset: 17000, Length: 65536, Hash: 0xdeadbeefcafef00d, Data: []byte("data")}
+	if data, err := json.Marshal(chunk.Ref()); err != nil || strings.Contains(string(data), "data") {
+		t.Errorf("chunk ref marshaled to %s, %v", data, err)
+	}
+
+	for _, input := range []string{`{"offset":0,"length":1,"hash":"xyz"}`, `{"offset":0,"length":1}`, `[]`} {
+		var ref fastcdc.ChunkRef
+		if err := json.Unmarshal([]byte(input), &ref); err == nil {
+			t.Errorf("%s: expected an error", input)
+		}
+	}
+}
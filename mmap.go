@@ -0,0 +1,52 @@
+package fastcdc
+
+import (
+	"os"
+)
+
+// ChunkMmap chunks the file at path, calling fn for each chunk, as Process does.
+// Where supported the file is memory-mapped read-only and chunked in place with
+// no copies, so files larger than RAM are chunked without double buffering;
+// the mapping is removed before ChunkMmap returns. On other platforms, and for
+// files too large to map in the address space, the file is read through the
+// chunker's internal buffer instead.
+//
+// Chunk.Data is only valid during the call to fn. Empty files produce no chunks,
+// and a file smaller than the minimum size is a single chunk. If fn returns an
+// error, ChunkMmap stops and returns that error.
+func ChunkMmap(path string, fn func(Chunk) error, opts ...Option) error {
+	// Validate options before touching the file
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+
+	data, unmap, err := mapFile(f, info.Size())
+	if err != nil {
+		return err
+	}
+
+	if data == nil {
+		// Not mapped, read the file instead
+		return NewChunkerFromConfig(f, cfg).Process(fn)
+	}
+	defer unmap()
+
+	c := newChunkerFromConfig(&cfg)
+	c.buf = data
+	c.external = true
+	c.eof = true
+
+	return c.Process(fn)
+}
@@ -0,0 +1,12 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package fastcdc
+
+import (
+	"os"
+)
+
+// mapFile never maps on this platform; ChunkMmap reads the file instead.
+func mapFile(_ *os.File, _ int64) (data []byte, unmap func(), err error) {
+	return nil, nil, nil
+}
@@ -0,0 +1,105 @@
+package fastcdc_test
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestChunkMmap(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024)}
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"Empty", nil},
+		{"SmallerThanMinSize", randBytes(100, 1)},
+		{"Large", randBytes(1024*1024, 2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			path := filepath.Join(dir, tt.name)
+			if err := os.WriteFile(path, tt.data, 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			ref, err := fastcdc.NewChunkerFromBytes(tt.data, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var want []fastcdc.Chunk
+
+			err = ref.Process(func(chunk fastcdc.Chunk) error {
+				want = append(want, chunk)
+
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var i int
+
+			err = fastcdc.ChunkMmap(path, func(chunk fastcdc.Chunk) error {
+				if i >= len(want) {
+					t.Fatalf("unexpected chunk %d", i)
+				}
+
+				if chunk.Offset != want[i].Offset || chunk.Length != want[i].Length ||
+					chunk.Hash != want[i].Hash || string(chunk.Data) != string(want[i].Data) {
+					t.Errorf("chunk %d differs: got offset %d length %d, want offset %d length %d",
+						i, chunk.Offset, chunk.Length, want[i].Offset, want[i].Length)
+				}
+
+				i++
+
+				return nil
+			}, opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if i != len(want) {
+				t.Errorf("got %d chunks, want %d", i, len(want))
+			}
+		})
+	}
+}
+
+func TestChunkMmapErrors(t *testing.T) {
+	t.Parallel()
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, randBytes(256*1024, 3), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	errStop := errors.New("stop")
+
+	err := fastcdc.ChunkMmap(path, func(fastcdc.Chunk) error { return errStop })
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected callback error, got %v", err)
+	}
+
+	err = fastcdc.ChunkMmap(path, func(fastcdc.Chunk) error { return nil }, fastcdc.WithTargetSize(0))
+	if !errors.Is(err, fastcdc.ErrInvalidTargetSize) {
+		t.Errorf("expected ErrInvalidTargetSize, got %v", err)
+	}
+
+	err = fastcdc.ChunkMmap(path+".missing", func(fastcdc.Chunk) error { return nil })
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("expected fs.ErrNotExist, got %v", err)
+	}
+}
@@ -0,0 +1,24 @@
+//go:build darwin || dragonfly || freebsd || linux || netbsd || openbsd
+
+package fastcdc
+
+import (
+	"math"
+	"os"
+	"syscall"
+)
+
+// mapFile maps size bytes of f read-only. It returns nil data, and no error,
+// when the file is empty or too large to map.
+func mapFile(f *os.File, size int64) (data []byte, unmap func(), err error) {
+	if size <= 0 || uint64(size) > math.MaxInt { //nolint:gosec // G115: size is positive
+		return nil, nil, nil
+	}
+
+	data, err = syscall.Mmap(int(f.Fd()), 0, int(size), syscall.PROT_READ, syscall.MAP_SHARED)
+	if err != nil {
+		return nil, nil, &os.PathError{Op: "mmap", Path: f.Name(), Err: err}
+	}
+
+	return data, func() { _ = syscall.Munmap(data) }, nil
+}
@@ -0,0 +1,95 @@
+package fastcdc
+
+import (
+	"errors"
+	"io"
+	"sort"
+)
+
+// ResetMulti resets the chunker to process the concatenation of readers as one
+// stream, like Reset(io.MultiReader(readers...)). Boundaries ignore where one
+// reader ends and the next begins, so chunks are identical to chunking the
+// concatenated data. Chunk.SourceIndex reports which reader holds the first
+// byte of each chunk; a chunk may span into the following readers.
+func (c *Chunker) ResetMulti(readers ...io.Reader) {
+	m := &multiSource{readers: readers}
+
+	c.Reset(m)
+	c.sources = m
+}
+
+// multiSource reads a list of readers sequentially and records the absolute
+// offset at which each of them starts.
+type multiSource struct {
+	readers []io.Reader
+	current int      // Index of the reader being read
+	starts  []uint64 // Offset of the first byte of each reader opened so far
+	read    uint64   // Bytes read so far
+}
+
+// Read implements io.Reader.
+func (m *multiSource) Read(p []byte) (int, error) {
+	for m.current < len(m.readers) {
+		if len(m.starts) == m.current {
+			m.starts = append(m.starts, m.read)
+		}
+
+		n, err := m.readers[m.current].Read(p)
+		m.read += uint64(n) //nolint:gosec // G115
+
+		if errors.Is(err, io.EOF) {
+			m.current++
+
+			if n == 0 {
+				continue
+			}
+
+			err = nil
+		}
+
+		return n, err
+	}
+
+	return 0, io.EOF
+}
+
+// index returns the index of the reader holding the byte at offset.
+// Empty readers share their start with the next one and are skipped.
+func (m *multiSource) index(offset uint64) int {
+	return sort.Search(len(m.starts), func(i int) bool { return m.starts[i] > offset }) - 1
+}
+
+// ChunkAll chunks each of readers as a separate stream, in order, calling fn
+// with the index of the reader and each of its chunks. Offsets start from 0
+// for every reader. Chunk.Data is only valid during the call to fn.
+//
+// When a reader fails, ChunkAll calls onErr with its index and the error,
+// after fn has been called for the chunks read before it. If onErr returns
+// true, ChunkAll moves on to the next reader; otherwise, or if onErr is nil, it
+// stops and returns the error. Invalid options are returned before any reader
+// is read.
+func ChunkAll(readers []io.Reader, fn func(src int, chunk Chunk), onErr func(src int, err error) bool,
+	opts ...Option,
+) error {
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return err
+	}
+
+	c := NewChunkerFromConfig(nil, cfg)
+
+	for src, r := range readers {
+		c.Reset(r)
+
+		err := c.Process(func(chunk Chunk) error {
+			fn(src, chunk)
+
+			return nil
+		})
+		if err != nil && (onErr == nil || !onErr(src, err)) {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+	"testing/iotest"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestResetMulti(t *testing.T) {
+	t.Parallel()
+
+	// Parts of varying sizes, including an empty one and ones smaller than a chunk
+	sizes := []int{300 * 1024, 0, 1000, 700 * 1024, 64 * 1024, 5}
+	data := randBytes(1064*1024+1005, 1272)
+
+	var (
+		parts  []io.Reader
+		starts []uint64
+	)
+
+	offset := 0
+
+	for _, size := range sizes {
+		parts = append(parts, bytes.NewReader(data[offset:offset+size]))
+		starts = append(starts, uint64(offset)) //nolint:gosec // G115
+		offset += size
+	}
+
+	want, err := fastcdc.NewChunker(bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := fastcdc.NewChunker(bytes.NewReader(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	got.ResetMulti(parts...)
+
+	for {
+		g, gErr := got.Next()
+		w, wErr := want.Next()
+
+		if !errors.Is(gErr, wErr) {
+			t.Fatalf("Error mismatch: got %v, want %v", gErr, wErr)
+		}
+
+		if wErr != nil {
+			break
+		}
+
+		if g.Offset != w.Offset || g.Length != w.Length || g.Hash != w.Hash {
+			t.Fatalf("Chunk mismatch: got %d+%d, want %d+%d", g.Offset, g.Length, w.Offset, w.Length)
+		}
+
+		// The source holds the first byte: it starts at or before the chunk and is not empty
+		i := g.SourceIndex
+		if starts[i] > g.Offset || g.Offset >= starts[i]+uint64(sizes[i]) { //nolint:gosec // G115
+			t.Errorf("Chunk at %d has SourceIndex %d covering [%d, %d)", g.Offset, i, starts[i], starts[i]+uint64(sizes[i])) //nolint:gosec // G115
+		}
+	}
+}
+
+func TestChunkAll(t *testing.T) {
+	t.Parallel()
+
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024)}
+	first, last := randBytes(200*1024, 1331), randBytes(100*1024, 1332)
+
+	// The second reader fails after some data
+	newReaders := func() []io.Reader {
+		return []io.Reader{
+			bytes.NewReader(first),
+			io.MultiReader(bytes.NewReader(randBytes(50*1024, 1333)), iotest.ErrReader(errTestRead)),
+			bytes.NewReader(last),
+		}
+	}
+
+	// lengths returns the chunk lengths of data chunked on its own
+	lengths := func(data []byte) []uint32 {
+		var got []uint32
+
+		err := mustChunker(t, bytes.NewReader(data), opts...).Process(func(chunk fastcdc.Chunk) error {
+			got = append(got, chunk.Length)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return got
+	}
+
+	var (
+		got    = make([][]uint32, 3)
+		failed []int
+	)
+
+	err := fastcdc.ChunkAll(newReaders(), func(src int, chunk fastcdc.Chunk) {
+		var end uint64
+		for _, length := range got[src] {
+			end += uint64(length)
+		}
+
+		if chunk.Offset != end {
+			t.Errorf("reader %d: chunk at %d, want %d", src, chunk.Offset, end)
+		}
+
+		got[src] = append(got[src], chunk.Length)
+	}, func(src int, err error) bool {
+		if !errors.Is(err, errTestRead) {
+			t.Errorf("reader %d: got %v, want the read error", src, err)
+		}
+
+		failed = append(failed, src)
+
+		return true
+	}, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !slices.Equal(failed, []int{1}) {
+		t.Errorf("onErr called for readers %v, want [1]", failed)
+	}
+
+	if !slices.Equal(got[0], lengths(first)) || !slices.Equal(got[2], lengths(last)) {
+		t.Error("chunks differ from chunking each reader on its own")
+	}
+
+	// Stopping at the failure, or without onErr, returns the error
+	for _, onErr := range []func(int, error) bool{func(int, error) bool { return false }, nil} {
+		var sources []int
+
+		err := fastcdc.ChunkAll(newReaders(), func(src int, _ fastcdc.Chunk) {
+			sources = append(sources, src)
+		}, onErr, opts...)
+		if !errors.Is(err, errTestRead) {
+			t.Errorf("got %v, want the read error", err)
+		}
+
+		if slices.Contains(sources, 2) {
+			t.Error("the reader after the failure was chunked")
+		}
+	}
+
+	err = fastcdc.ChunkAll(nil, func(int, fastcdc.Chunk) {}, nil, fastcdc.WithMinSize(0))
+	if !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("invalid options: got %v, want ErrInvalidMinSize", err)
+	}
+}
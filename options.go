@@ -1,8 +1,15 @@
 package fastcdc
 
 import (
+	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"fmt"
+	"hash"
+	"io"
+	"math"
+	"math/bits"
+	"slices"
 )
 
 var (
@@ -18,14 +25,106 @@ var (
 	// ErrInvalidMaxSize is returned when maxSize is 0.
 	ErrInvalidMaxSize = errors.New("maxSize must be greater than 0")
 
+	// ErrMaxSizeTooLarge is returned when maxSize does not fit in an int, which
+	// limits it to math.MaxInt32 on 32-bit platforms.
+	ErrMaxSizeTooLarge = errors.New("maxSize exceeds the largest size supported on this platform")
+
 	// ErrMaxSizeTooSmall is returned when maxSize is not greater than targetSize.
 	ErrMaxSizeTooSmall = errors.New("maxSize must be greater than targetSize")
 
+	// ErrInvalidMaxSizeRatio is returned when the maxSize ratio is below MinMaxSizeRatio
+	// or derives a maxSize that does not fit in a uint32.
+	ErrInvalidMaxSizeRatio = errors.New("maxSize ratio must be at least 2 and derive a maxSize below 4 GiB")
+
 	// ErrInvalidNormLevel is returned when normLevel is not between 0 and 8.
 	ErrInvalidNormLevel = errors.New("normLevel must be between 0 and 8")
 
+	// ErrInvalidNormSize is returned when an explicit normSize is not strictly between minSize and maxSize.
+	ErrInvalidNormSize = errors.New("normSize must be greater than minSize and less than maxSize")
+
+	// ErrInvalidNormRegions is returned when the number of normalization regions is
+	// not between 2 and 8, or more than two are combined with WithMasks.
+	ErrInvalidNormRegions = errors.New("normalization regions must be between 2 and 8, without explicit masks")
+
+	// ErrInvalidNormStrength is returned when the normalization strength is not less than the mask bits.
+	ErrInvalidNormStrength = errors.New("normalization strength must be less than the number of mask bits")
+
+	// ErrUnsupportedAlgorithmVersion is returned when the algorithm version is unknown.
+	ErrUnsupportedAlgorithmVersion = errors.New("unsupported algorithm version")
+
+	// ErrBufferTooSmall is returned when a caller-supplied buffer cannot hold maxSize bytes.
+	ErrBufferTooSmall = errors.New("buffer must hold at least maxSize bytes")
+
+	// ErrSharedBuffer is returned when WithBuffer is used for a Config or pool,
+	// whose chunkers would all share the buffer.
+	ErrSharedBuffer = errors.New("a caller-supplied buffer cannot be shared by several chunkers")
+
+	// ErrBufferTooLarge is returned when the internal buffer would exceed the maximum buffer size.
+	ErrBufferTooLarge = errors.New("bufferSize exceeds the maximum buffer size")
+
+	// ErrInvalidTable is returned when a custom Gear table is all zeros.
+	ErrInvalidTable = errors.New("gear table must not be all zeros")
+
+	// ErrTableWithSeed is returned when both a custom Gear table and a seed are set.
+	ErrTableWithSeed = errors.New("custom gear table and seed are mutually exclusive")
+
+	// ErrUnsupportedRollingHash is returned when the rolling hash kind is unknown.
+	ErrUnsupportedRollingHash = errors.New("unsupported rolling hash")
+
+	// ErrGearTableWithRabin is returned when a Gear table or seed is set with HashRabin.
+	ErrGearTableWithRabin = errors.New("gear table and seed require the Gear rolling hash")
+
+	// ErrInvalidTailPolicy is returned when the tail policy is unknown.
+	ErrInvalidTailPolicy = errors.New("invalid tail policy")
+
+	// ErrShortTail is returned by the streaming API with TailError when the final
+	// chunk is shorter than minSize.
+	ErrShortTail = errors.New("final chunk is shorter than minSize")
+
+	// ErrDigestWithoutHash is returned when both WithoutHash and a chunk digest are set.
+	ErrDigestWithoutHash = errors.New("chunk digest and WithoutHash are mutually exclusive")
+
+	// ErrChunkIDWithoutHash is returned when both WithoutHash and WithChunkID are set.
+	ErrChunkIDWithoutHash = errors.New("chunk ID and WithoutHash are mutually exclusive")
+
+	// ErrWideWithoutHash is returned when both WithoutHash and WithWideFingerprint are set.
+	ErrWideWithoutHash = errors.New("wide fingerprint and WithoutHash are mutually exclusive")
+
 	// ErrInvalidBufferSize is returned when bufferSize is 0.
 	ErrInvalidBufferSize = errors.New("bufferSize must be greater than 0")
+
+	// ErrInvalidMasks is returned when the large mask is 0 or the small mask has more bits set than it.
+	ErrInvalidMasks = errors.New("maskL must be non-zero and have at least as many bits set as maskS")
+
+	// ErrInvalidMinChunkCount is returned when the minimum chunk count is less than 1.
+	ErrInvalidMinChunkCount = errors.New("minChunkCount must be at least 1")
+
+	// ErrInvalidFixedSize is returned when the size of WithFixedSize is 0.
+	ErrInvalidFixedSize = errors.New("fixed chunk size must be greater than 0")
+
+	// ErrFixedSizeConflict is returned when WithFixedSize is combined with an option
+	// of content-defined chunking.
+	ErrFixedSizeConflict = errors.New("fixed-size chunking cannot be combined with content-defined chunking options")
+
+	// ErrInvalidTargetBits is returned when the bit count of WithTargetBits is not between 1 and 30.
+	ErrInvalidTargetBits = errors.New("target bits must be between 1 and 30")
+
+	// ErrTargetBitsWithMasks is returned when both WithTargetBits and WithMasks are set.
+	ErrTargetBitsWithMasks = errors.New("target bits and explicit masks are mutually exclusive")
+
+	// ErrPartialChunksConflict is returned when WithPartialChunks is combined with
+	// an option that needs every chunk whole in the buffer.
+	ErrPartialChunksConflict = errors.New("partial chunks cannot be combined with options that need whole chunks")
+
+	// ErrInvalidRetryCount is returned when the count of WithRetry is negative.
+	ErrInvalidRetryCount = errors.New("retry count must not be negative")
+
+	// ErrInvalidDelimiterWindow is returned when the window of WithPreferredDelimiter is less than 1.
+	ErrInvalidDelimiterWindow = errors.New("delimiter window must be at least 1")
+
+	// ErrUnsupportedOption is returned when an API that does not use the streaming
+	// chunker, such as ChunkFileParallel, is given an option it cannot apply.
+	ErrUnsupportedOption = errors.New("option not supported by this API")
 )
 
 const (
@@ -42,44 +141,411 @@ const (
 	// Determines the size of the normalization region: (targetSize - minSize) / 2^normLevel.
 	DefaultNormLevel = 2
 
+	// DefaultNormStrength is the default normalization strength (1)
+	// The small mask used in the normalized region has this many fewer bits than the large mask.
+	DefaultNormStrength = 1
+
+	// DefaultMaxBufferSize is the default ceiling for the internal buffer size (64 MiB).
+	DefaultMaxBufferSize = 64 * 1024 * 1024
+
+	// maxSafeSize is the largest maxSize for which sizes fit in both uint32 and int,
+	// leaving room for the byte the streaming API buffers past a maximum-size chunk.
+	maxSafeSize = min(math.MaxUint32, math.MaxInt-1)
+
+	// RecommendedMaxSizeRatio is the recommended maxSize to targetSize ratio (4),
+	// as used by the defaults and benchmark configurations.
+	RecommendedMaxSizeRatio = 4
+
+	// MinMaxSizeRatio is the smallest ratio accepted by WithMaxChunkSizeRatio (2).
+	// Below it, a large share of chunks is force-cut at maxSize.
+	MinMaxSizeRatio = 2
+
+	// AlgorithmV1 is the original boundary algorithm: Gear hash with normalized
+	// chunking and a minSize fast-forward. Its boundaries are frozen.
+	AlgorithmV1 = 1
+
+	// LatestAlgorithmVersion is the algorithm version used by default.
+	LatestAlgorithmVersion = AlgorithmV1
+
+	// bufferChunks is the number of maximum-size chunks RecommendedBufferSize makes room for.
+	bufferChunks = 4
+
 	// DefaultBufferSize is the default internal buffer size for the streaming API (512 KiB).
 	// This is 2x the default max chunk size, providing efficient buffering.
 	DefaultBufferSize = 512 * 1024
 )
 
+// RollingHash selects the rolling hash used to find boundaries.
+type RollingHash uint8
+
+const (
+	// HashGear is the Gear hash of FastCDC, the default: one shift, add and table
+	// lookup per byte.
+	HashGear RollingHash = iota
+
+	// HashRabin is a Rabin fingerprint over a 64-byte window using RabinPolynomial,
+	// as in restic and LBFS. It is several times slower than HashGear.
+	HashRabin
+)
+
+// TailPolicy selects how the streaming API handles a final chunk shorter than minSize.
+type TailPolicy uint8
+
+const (
+	// TailEmit returns the short final chunk like any other, the default.
+	TailEmit TailPolicy = iota
+
+	// TailMerge appends a short final chunk to the previous chunk, which may then
+	// exceed maxSize by up to minSize-1 bytes. An input shorter than minSize is
+	// still returned as a single short chunk.
+	TailMerge
+
+	// TailError returns ErrShortTail instead of a short final chunk, including
+	// for an input shorter than minSize.
+	TailError
+)
+
 // Option is a function that configures a Chunker or ChunkerCore.
 type Option func(*config) error
 
 // config holds the configuration for chunking.
 type config struct {
-	minSize    uint32
-	targetSize uint32
-	maxSize    uint32
-	normLevel  uint8
-	seed       uint64
-	bufferSize int
+	minSize         uint32
+	targetSize      uint32
+	maxSize         uint32
+	normLevel       uint8
+	normStrength    uint8
+	normSize        uint32 // Explicit normalization boundary (0 to derive from normLevel)
+	maxRatio        float64
+	version         uint8
+	rolling         RollingHash
+	seed            uint64
+	boundary        uint64
+	masks           *[2]uint64   // Explicit maskS and maskL (nil to derive from targetSize)
+	table           *[256]uint64 // Custom Gear table (nil to generate from seed)
+	bufferSize      int
+	maxBuffer       int
+	guardedData     bool
+	copyData        bool
+	noHash          bool
+	tail            TailPolicy
+	frameMagic      []byte // Forces a boundary before each occurrence (NewFrameAwareChunker)
+	absoluteOffsets bool   // Report offsets relative to the start of the ReaderAt (NewRangeChunker)
+	adaptive        bool   // Adjust the masks to keep the average chunk size near targetSize
+	wide            bool   // Populate Chunk.Hash128
+	buffer          []byte // Caller-owned internal buffer (WithBuffer)
+	normRegions     int    // Number of mask regions (0 for the default two)
+	minChunks       int
+	stats           bool
+	digest          func() hash.Hash
+	progress        func(bytesProcessed uint64)
+	noUnroll        bool // Use the plain Gear loop (WithUnroll(false))
+	delimiter       byte // Byte content-defined cuts move after (WithPreferredDelimiter)
+	delimWindow     int  // Distance a cut may move to reach delimiter (0 to disable)
+	tee             io.Writer
+	hashFromStart   bool
+	fixedSize       uint32 // Cut every fixedSize bytes instead of by content (0 for CDC)
+	retries         int
+	targetBits      uint8  // Bits of maskL (WithTargetBits, 0 to derive from targetSize)
+	partial         bool   // Return chunks longer than the buffer in parts (WithPartialChunks)
+	chunkID         []byte // Namespace hashed before the data into Chunk.ID (nil to disable)
 }
 
-// validate checks that the configuration is valid.
+// defaultConfig returns the configuration used before any options are applied.
+func defaultConfig() config {
+	return config{
+		minSize:      DefaultMinSize,
+		targetSize:   DefaultTargetSize,
+		maxSize:      DefaultMaxSize,
+		normLevel:    DefaultNormLevel,
+		normStrength: DefaultNormStrength,
+		seed:         0,
+		bufferSize:   DefaultBufferSize,
+		maxBuffer:    DefaultMaxBufferSize,
+		version:      LatestAlgorithmVersion,
+	}
+}
+
+// Config is a validated chunker configuration. Build it once with NewConfig and
+// pass it to NewChunkerFromConfig to create chunkers without re-applying and
+// re-validating options. A Config is an immutable value that is safe to copy and
+// to share between goroutines.
+type Config struct {
+	cfg  config
+	core ChunkerCore
+}
+
+// NewConfig applies and validates opts, returning a Config for the streaming API.
+// It returns the same errors as NewChunker, and ErrSharedBuffer with WithBuffer.
+func NewConfig(opts ...Option) (Config, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return Config{}, err
+		}
+	}
+
+	if cfg.buffer != nil {
+		return Config{}, ErrSharedBuffer
+	}
+
+	if err := cfg.validate(); err != nil {
+		return Config{}, err
+	}
+
+	if err := cfg.validateBuffer(); err != nil {
+		return Config{}, err
+	}
+
+	return Config{cfg: cfg, core: newChunkerCoreWithConfig(&cfg)}, nil
+}
+
+// Warnings describes settings of c that are valid but unlikely to be intended,
+// one sentence each, such as a normalization region that vanished or a maxSize
+// below MinMaxSizeRatio times targetSize. It returns nil if there is nothing to
+// report. Log them at startup, or fail tests on them, when tuning the sizes and
+// normalization.
+func (c Config) Warnings() []string {
+	var warnings []string
+
+	if c.cfg.fixedSize == 0 && c.cfg.normSize == 0 && c.core.normSize == c.core.minSize {
+		warnings = append(warnings, fmt.Sprintf("normalization region is empty: (targetSize - minSize) >> normLevel "+
+			"is 0 with targetSize %d, minSize %d and normLevel %d, so the small mask is never tested; "+
+			"lower normLevel or set WithNormSize", c.core.targetSize, c.core.minSize, c.core.normLevel))
+	}
+
+	if c.cfg.fixedSize == 0 && c.cfg.maxRatio == 0 && uint64(c.core.maxSize) < MinMaxSizeRatio*uint64(c.core.targetSize) {
+		warnings = append(warnings, fmt.Sprintf("maxSize %d is less than %d times targetSize %d, so many chunks "+
+			"are cut at maxSize rather than by content; raise maxSize or use WithMaxChunkSizeRatio",
+			c.core.maxSize, MinMaxSizeRatio, c.core.targetSize))
+	}
+
+	return warnings
+}
+
+// validate checks that the configuration is valid, returning every violated
+// constraint joined with errors.Join so that all of them can be fixed at once,
+// or the error itself if only one is violated. Each can be tested with errors.Is.
 func (c *config) validate() error {
+	var errs []error
+
+	if c.fixedSize != 0 {
+		if option := c.fixedSizeConflict(); option != "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrFixedSizeConflict, option))
+		}
+
+		// Every chunk but the last one has exactly fixedSize bytes
+		c.minSize, c.targetSize, c.maxSize = c.fixedSize, c.fixedSize, c.fixedSize
+	}
+
 	if c.minSize == 0 {
-		return ErrInvalidMinSize
+		errs = append(errs, ErrInvalidMinSize)
+	}
+
+	if c.maxRatio != 0 {
+		maxSize := float64(c.targetSize) * c.maxRatio
+		if maxSize > math.MaxUint32 {
+			errs = append(errs, fmt.Errorf("%w: targetSize (%d), ratio (%g)", ErrInvalidMaxSizeRatio, c.targetSize, c.maxRatio))
+		} else {
+			c.maxSize = uint32(maxSize)
+		}
 	}
 
-	if c.targetSize <= c.minSize {
-		return fmt.Errorf("%w: targetSize (%d), minSize (%d)", ErrTargetSizeTooSmall, c.targetSize, c.minSize)
+	if c.fixedSize == 0 && c.targetSize <= c.minSize {
+		errs = append(errs, fmt.Errorf("%w: targetSize (%d), minSize (%d)", ErrTargetSizeTooSmall, c.targetSize, c.minSize))
 	}
 
-	if c.maxSize <= c.targetSize {
-		return fmt.Errorf("%w: maxSize (%d), targetSize (%d)", ErrMaxSizeTooSmall, c.maxSize, c.targetSize)
+	if c.fixedSize == 0 && c.maxSize <= c.targetSize {
+		errs = append(errs, fmt.Errorf("%w: maxSize (%d), targetSize (%d)", ErrMaxSizeTooSmall, c.maxSize, c.targetSize))
+	}
+
+	// Positions are computed as int, which is 32 bits wide on 386 and arm
+	if uint64(c.maxSize) > maxSafeSize {
+		errs = append(errs, fmt.Errorf("%w: maxSize (%d), limit (%d)", ErrMaxSizeTooLarge, c.maxSize, uint64(maxSafeSize)))
 	}
 
 	if c.normLevel > 8 {
-		return fmt.Errorf("%w: got %d", ErrInvalidNormLevel, c.normLevel)
+		errs = append(errs, fmt.Errorf("%w: got %d", ErrInvalidNormLevel, c.normLevel))
+	}
+
+	if c.normSize != 0 && (c.normSize <= c.minSize || c.normSize >= c.maxSize) {
+		errs = append(errs, fmt.Errorf("%w: normSize (%d), minSize (%d), maxSize (%d)",
+			ErrInvalidNormSize, c.normSize, c.minSize, c.maxSize))
+	}
+
+	// The masks are derived from the sizes, so they are only checked for valid
+	// sizes, and unused with WithFixedSize
+	sizesValid := len(errs) == 0 && c.fixedSize == 0
+
+	if c.table != nil && c.seed != 0 {
+		errs = append(errs, ErrTableWithSeed)
+	}
+
+	if c.rolling == HashRabin && (c.table != nil || c.seed != 0) {
+		errs = append(errs, ErrGearTableWithRabin)
+	}
+
+	if c.noHash && c.digest != nil {
+		errs = append(errs, ErrDigestWithoutHash)
+	}
+
+	if c.noHash && c.chunkID != nil {
+		errs = append(errs, ErrChunkIDWithoutHash)
+	}
+
+	if c.noHash && c.wide {
+		errs = append(errs, ErrWideWithoutHash)
+	}
+
+	if c.masks != nil {
+		if c.masks[1] == 0 || bits.OnesCount64(c.masks[0]) > bits.OnesCount64(c.masks[1]) {
+			errs = append(errs, fmt.Errorf("%w: maskS (%#x), maskL (%#x)", ErrInvalidMasks, c.masks[0], c.masks[1]))
+		}
+	} else if sizesValid {
+		if _, _, _, bits := c.computeMasks(); c.normStrength >= bits {
+			errs = append(errs, fmt.Errorf("%w: normStrength (%d), bits (%d)", ErrInvalidNormStrength, c.normStrength, bits))
+		} else if c.normRegions > 2 && int(c.normStrength)*(c.normRegions-1) >= int(bits) {
+			errs = append(errs, fmt.Errorf("%w: normStrength (%d) over %d regions, bits (%d)",
+				ErrInvalidNormStrength, c.normStrength, c.normRegions, bits))
+		}
+	}
+
+	if c.targetBits != 0 && c.masks != nil {
+		errs = append(errs, ErrTargetBitsWithMasks)
+	}
+
+	if c.partial {
+		if option := c.partialChunksConflict(); option != "" {
+			errs = append(errs, fmt.Errorf("%w: %s", ErrPartialChunksConflict, option))
+		}
+	}
+
+	if c.normRegions > 2 && c.masks != nil {
+		errs = append(errs, fmt.Errorf("%w: %d regions with explicit masks", ErrInvalidNormRegions, c.normRegions))
+	}
+
+	if c.tail == TailMerge && uint64(c.maxSize)+uint64(c.minSize) > maxSafeSize+1 {
+		errs = append(errs, fmt.Errorf("%w: maxSize (%d) plus merged tail, limit (%d)",
+			ErrMaxSizeTooLarge, c.maxSize, uint64(maxSafeSize)))
+	}
+
+	if uint64(c.maxSize)+uint64(len(c.frameMagic)) > maxSafeSize+1 {
+		errs = append(errs, fmt.Errorf("%w: maxSize (%d) plus frame magic, limit (%d)",
+			ErrMaxSizeTooLarge, c.maxSize, uint64(maxSafeSize)))
+	}
+
+	// The caller's buffer is used as is, it cannot be enlarged
+	if c.buffer != nil && len(c.buffer) < c.minBufferSize() {
+		errs = append(errs, fmt.Errorf("%w: buffer (%d), required (%d)", ErrBufferTooSmall, len(c.buffer), c.minBufferSize()))
+	}
+
+	switch len(errs) {
+	case 0:
+	case 1:
+		return errs[0]
+	default:
+		return errors.Join(errs...)
+	}
+
+	if c.buffer != nil {
+		c.bufferSize = len(c.buffer)
+
+		return nil
 	}
-	// Auto-adjust buffer size if needed
-	if c.bufferSize < int(c.maxSize) {
-		c.bufferSize = int(c.maxSize)
+
+	// Auto-adjust buffer size if needed: it must hold more than one maximum-size
+	// chunk for the streaming API to detect the final chunk, and a buffer that
+	// barely does so needs a refill for nearly every chunk
+	if c.bufferSize < c.minBufferSize() {
+		c.bufferSize = c.recommendedBufferSize()
+	}
+
+	return nil
+}
+
+// lookahead returns how many bytes the streaming API reads ahead, unless at
+// EOF, before returning a chunk: enough to tell whether a maximum-size chunk is
+// the final one, or with TailMerge whether a short tail follows it, and whole
+// frame magics starting within the chunk.
+func (c *config) lookahead() int {
+	n := int(c.maxSize) + 1
+	if c.tail == TailMerge {
+		n = int(c.maxSize) + int(c.minSize)
+	}
+
+	// A frame magic starting just before maxSize must be seen whole
+	return max(n, int(c.maxSize)-1+len(c.frameMagic))
+}
+
+// minPartialBuffer is the smallest buffer of WithPartialChunks: one byte for a
+// part and one held back to tell whether the input ends after it.
+const minPartialBuffer = 2
+
+// minBufferSize returns the smallest internal buffer the streaming API can
+// chunk with: the lookahead, or minPartialBuffer with WithPartialChunks.
+func (c *config) minBufferSize() int {
+	if c.partial {
+		return minPartialBuffer
+	}
+
+	return c.lookahead()
+}
+
+// partialChunksConflict returns the first option set alongside WithPartialChunks
+// that needs whole chunks in the buffer, or "" if there is none.
+func (c *config) partialChunksConflict() string {
+	switch {
+	case c.wide:
+		return "WithWideFingerprint"
+	case c.delimWindow != 0:
+		return "WithPreferredDelimiter"
+	case c.minChunks > 1:
+		return "WithMinChunkCount"
+	case c.tail == TailMerge:
+		return "TailMerge"
+	case c.frameMagic != nil:
+		return "NewFrameAwareChunker"
+	}
+
+	return ""
+}
+
+// recommendedBufferSize returns the buffer size that amortizes reads over
+// several chunks: bufferChunks maximum-size chunks, at least DefaultBufferSize
+// and the lookahead, and at most the maximum buffer size unless the lookahead
+// requires more.
+func (c *config) recommendedBufferSize() int {
+	size := min(max(uint64(c.maxSize)*bufferChunks, DefaultBufferSize), uint64(c.maxBuffer)) //nolint:gosec // G115
+
+	return max(int(size), c.lookahead()) //nolint:gosec // G115: bounded by maxBuffer
+}
+
+// RecommendedBufferSize returns the internal buffer size recommended for the
+// streaming API with opts: room for four maximum-size chunks, and at least
+// DefaultBufferSize, so that reads are amortized over several chunks. It is
+// capped at the maximum buffer size. This is also the size used when the
+// configured buffer cannot hold a maximum-size chunk.
+func RecommendedBufferSize(opts ...Option) (int, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return 0, err
+	}
+
+	return cfg.recommendedBufferSize(), nil
+}
+
+// validateBuffer checks that the internal buffer of the streaming API,
+// after adjustment to maxSize, stays within the configured ceiling. A buffer
+// supplied with WithBuffer is not allocated and is not checked.
+func (c *config) validateBuffer() error {
+	if c.buffer == nil && c.bufferSize > c.maxBuffer {
+		return fmt.Errorf("%w: bufferSize (%d), maxBufferSize (%d)", ErrBufferTooLarge, c.bufferSize, c.maxBuffer)
 	}
 
 	return nil
@@ -87,22 +553,22 @@ func (c *config) validate() error {
 
 // computeMasks calculates the maskS and maskL for normalized chunking.
 func (c *config) computeMasks() (maskS, maskL uint64, normSize uint32, bits uint8) {
-	// Calculate bits from targetSize
-	bits = 0
-
-	tmp := c.targetSize
-	for tmp > 1 {
-		tmp >>= 1
-		bits++
+	// Calculate bits from targetSize, rounded down to a power of two, unless
+	// set with WithTargetBits
+	bits = c.targetBits
+	if bits == 0 {
+		for tmp := c.targetSize; tmp > 1; tmp >>= 1 {
+			bits++
+		}
 	}
 
 	// Base mask (for targetSize)
 	maskL = (uint64(1) << bits) - 1
 
 	// Smaller mask for normalization region (more aggressive cutting)
-	// maskS has fewer bits set, making it easier to match
-	if bits > 0 {
-		maskS = (uint64(1) << (bits - 1)) - 1
+	// maskS has normStrength fewer bits set, making it easier to match
+	if bits > c.normStrength {
+		maskS = (uint64(1) << (bits - c.normStrength)) - 1
 	} else {
 		maskS = 0
 	}
@@ -112,9 +578,23 @@ func (c *config) computeMasks() (maskS, maskL uint64, normSize uint32, bits uint
 	normRange := c.targetSize - c.minSize
 	normSize = c.minSize + (normRange >> c.normLevel)
 
+	if c.normSize != 0 {
+		normSize = c.normSize
+	}
+
+	if c.masks != nil {
+		maskS, maskL = c.masks[0], c.masks[1]
+		bits = maskBits(maskL)
+	}
+
 	return maskS, maskL, normSize, bits
 }
 
+// maskBits returns the number of bits set in mask.
+func maskBits(mask uint64) uint8 {
+	return uint8(bits.OnesCount64(mask)) //nolint:gosec // G115
+}
+
 // WithMinSize sets the minimum chunk size.
 func WithMinSize(size uint32) Option {
 	return func(c *config) error {
@@ -141,7 +621,38 @@ func WithTargetSize(size uint32) Option {
 	}
 }
 
-// WithMaxSize sets the maximum chunk size.
+// WithTargetBits sets the number of bits of the mask tested past normSize,
+// maskL = 1<<bits - 1, instead of deriving it from targetSize, which rounds
+// targetSize down to a power of two: a targetSize of 48 KiB tests the same 15
+// bits as 32 KiB. Past normSize, a boundary is then expected every 2^bits
+// bytes; the average chunk size also depends on minSize and normalization, see
+// EstimatedChunkCount. maskS has WithNormalizationStrength fewer bits, and
+// targetSize still determines normSize. bits must be between 1 and 30,
+// otherwise ErrInvalidTargetBits is returned, and cannot be combined with
+// WithMasks.
+func WithTargetBits(bits uint8) Option {
+	return func(c *config) error {
+		if bits < 1 || bits > 30 {
+			return fmt.Errorf("%w: got %d", ErrInvalidTargetBits, bits)
+		}
+
+		c.targetBits = bits
+
+		return nil
+	}
+}
+
+// WithAverageSize sets the target chunk size.
+// It is a synonym for WithTargetSize, matching the AverageSize terminology of
+// jotfs/fastcdc-go and restic/chunker. When both are given, the last one wins.
+func WithAverageSize(size uint32) Option {
+	return WithTargetSize(size)
+}
+
+// WithMaxSize sets the maximum chunk size. Below MinMaxSizeRatio times the
+// target size many chunks are forced cuts, which Config.Warnings reports.
+// It overrides a previous WithMaxChunkSizeRatio. On 32-bit platforms maxSize is
+// limited to math.MaxInt32, see ErrMaxSizeTooLarge.
 func WithMaxSize(size uint32) Option {
 	return func(c *config) error {
 		if size == 0 {
@@ -149,6 +660,26 @@ func WithMaxSize(size uint32) Option {
 		}
 
 		c.maxSize = size
+		c.maxRatio = 0
+
+		return nil
+	}
+}
+
+// WithMaxChunkSizeRatio sets the maximum chunk size to targetSize * ratio,
+// derived after all options are applied. It overrides a previous WithMaxSize.
+//
+// A maxSize close to targetSize makes many chunks end at the hard limit instead
+// of a content-defined boundary, which hurts both the distribution and dedup.
+// The ratio must be at least MinMaxSizeRatio (2); RecommendedMaxSizeRatio (4)
+// matches the defaults and the benchmark configurations.
+func WithMaxChunkSizeRatio(ratio float64) Option {
+	return func(c *config) error {
+		if math.IsNaN(ratio) || ratio < MinMaxSizeRatio {
+			return fmt.Errorf("%w: got %g", ErrInvalidMaxSizeRatio, ratio)
+		}
+
+		c.maxRatio = ratio
 
 		return nil
 	}
@@ -157,6 +688,8 @@ func WithMaxSize(size uint32) Option {
 // WithNormalization sets the normalization level.
 // Level 0 disables normalization (single-mask behavior).
 // Higher levels create a larger normalization region.
+// A level that shifts targetSize-minSize down to 0 leaves no region at all,
+// which Config.Warnings reports.
 func WithNormalization(level uint8) Option {
 	return func(c *config) error {
 		if level > 8 {
@@ -169,6 +702,126 @@ func WithNormalization(level uint8) Option {
 	}
 }
 
+// WithNormSize sets the normalization boundary directly, overriding the value
+// derived from the normalization level: the small mask applies to positions in
+// [minSize, size) and the large mask from size up to maxSize. size must be
+// greater than minSize and less than maxSize, and may exceed targetSize.
+func WithNormSize(size uint32) Option {
+	return func(c *config) error {
+		if size == 0 {
+			return fmt.Errorf("%w: got 0", ErrInvalidNormSize)
+		}
+
+		c.normSize = size
+
+		return nil
+	}
+}
+
+// WithNormRegions splits [minSize, maxSize) into n regions with their own masks,
+// generalizing the normalized region [minSize, normSize) and the standard region
+// [normSize, maxSize) used by default (n = 2). Region i starts at
+// minSize + i*(normSize-minSize), the last one ending at maxSize, and its mask
+// has normStrength*(n-1-i) fewer bits than the mask derived from targetSize, so
+// aggressiveness decreases from one region to the next. With n = 3 more chunks
+// end in the early regions, which lowers the standard deviation of chunk sizes
+// along with their mean; relative to the mean the spread does not shrink, so
+// compare configurations at equal mean size.
+//
+// n must be between 2 and 8 and cannot exceed 2 with WithMasks; normStrength
+// times n-1 must be less than the number of mask bits. More than two regions
+// use a slower scan and change the boundaries.
+func WithNormRegions(n int) Option {
+	return func(c *config) error {
+		if n < 2 || n > maxNormRegions {
+			return fmt.Errorf("%w: got %d", ErrInvalidNormRegions, n)
+		}
+
+		c.normRegions = n
+
+		return nil
+	}
+}
+
+// WithPreferredDelimiter makes the streaming API move each content-defined
+// boundary by up to window bytes, forward or back, so that the chunk ends just
+// after the nearest occurrence of b, such as '\n' to keep the records of NDJSON
+// or log files whole. When two occurrences are equally near the shorter chunk
+// wins, and the boundary stays where it was if there is none in the window.
+// Chunks stay within minSize and maxSize, cuts forced at maxSize move back
+// only, and the tail of the stream and cuts before a frame magic are not moved.
+// Chunk.Hash is still the fingerprint where the boundary was found.
+//
+// Moving a cut changes where the next chunk starts, so boundaries depend a
+// little more on the data before them: after an edit, chunking takes slightly
+// longer to re-synchronize, which costs some deduplication. The ChunkerCore API
+// is unaffected.
+func WithPreferredDelimiter(b byte, window int) Option {
+	return func(c *config) error {
+		if window < 1 {
+			return fmt.Errorf("%w: got %d", ErrInvalidDelimiterWindow, window)
+		}
+
+		c.delimiter = b
+		c.delimWindow = window
+
+		return nil
+	}
+}
+
+// WithAlgorithmVersion pins the boundary-producing algorithm to version v.
+//
+// Each released version is frozen: for the same input and options it produces the
+// same boundaries in every later release, whatever optimizations are made. Future
+// algorithm changes are added as new, opt-in versions. Long-lived dedup stores
+// should pin the version they were built with to rule out boundary drift.
+// The default is LatestAlgorithmVersion.
+func WithAlgorithmVersion(v int) Option {
+	return func(c *config) error {
+		if v != AlgorithmV1 {
+			return fmt.Errorf("%w: got %d", ErrUnsupportedAlgorithmVersion, v)
+		}
+
+		c.version = uint8(v)
+
+		return nil
+	}
+}
+
+// WithTailPolicy selects how the streaming API handles a final chunk shorter
+// than minSize: TailEmit (default), TailMerge or TailError. It does not apply
+// to ChunkerCore.
+func WithTailPolicy(policy TailPolicy) Option {
+	return func(c *config) error {
+		if policy > TailError {
+			return fmt.Errorf("%w: got %d", ErrInvalidTailPolicy, policy)
+		}
+
+		c.tail = policy
+
+		return nil
+	}
+}
+
+// WithRollingHash selects the rolling hash: HashGear (default) or HashRabin.
+//
+// HashRabin trades speed for the properties of Rabin fingerprints, such as a
+// fingerprint that only depends on the last 64 bytes, and eases migration from
+// Rabin-based systems. Chunk sizes, normalization and masks work as with Gear,
+// but the boundaries differ from those of both Gear and restic, which does not
+// normalize. WithSeed and WithTable only apply to HashGear.
+func WithRollingHash(kind RollingHash) Option {
+	return func(c *config) error {
+		if kind > HashRabin {
+			return fmt.Errorf("%w: got %d", ErrUnsupportedRollingHash, kind)
+		}
+
+		c.rolling = kind
+
+		return nil
+	}
+}
+
 // WithSeed sets a custom seed for the Gear hash table.
 // Using a non-zero seed will allocate a per-instance table (2 KiB).
 func WithSeed(seed uint64) Option {
@@ -179,8 +832,131 @@ func WithSeed(seed uint64) Option {
 	}
 }
 
+// WithMasks sets the masks tested in the normalized region [minSize, normSize)
+// and in [normSize, maxSize) directly, bypassing their derivation from targetSize
+// and WithNormalizationStrength. This is meant for matching reference vectors of
+// other implementations. minSize, normSize and maxSize still apply, and targetSize
+// only determines normSize. maskL must be non-zero and maskS must not have more
+// bits set than maskL, otherwise validation returns ErrInvalidMasks.
+func WithMasks(maskS, maskL uint64) Option {
+	return func(c *config) error {
+		c.masks = &[2]uint64{maskS, maskL}
+
+		return nil
+	}
+}
+
+// WithBoundaryValue sets the value the masked fingerprint must equal at a chunk
+// boundary, for compatibility with FastCDC variants that test (fp & mask) == mask
+// or a magic value instead of (fp & mask) == 0. Only the bits of v covered by each
+// mask are compared. The default is 0.
+func WithBoundaryValue(v uint64) Option {
+	return func(c *config) error {
+		c.boundary = v
+
+		return nil
+	}
+}
+
+// WithRandomSeed seeds the Gear hash table from crypto/rand, so an attacker who
+// knows the other parameters cannot craft input that forces pathological chunk
+// sizes. The chosen seed is available from Seed and can be passed to WithSeed to
+// reproduce the same boundaries. All chunkers built from one Config or pool share
+// the seed chosen when the options were applied.
+func WithRandomSeed() Option {
+	return func(c *config) error {
+		var b [8]byte
+
+		// A zero seed selects the default table, so draw again on the unlikely zero
+		for c.seed == 0 {
+			if _, err := rand.Read(b[:]); err != nil {
+				return fmt.Errorf("reading random seed: %w", err)
+			}
+
+			c.seed = binary.LittleEndian.Uint64(b[:])
+		}
+
+		return nil
+	}
+}
+
+// WithTable installs a custom Gear hash table verbatim, for bit-for-bit
+// compatibility with another CDC implementation that ships its own table.
+// The table must not be all zeros, which would never produce a boundary.
+//
+// WithTable and WithSeed are mutually exclusive: the seed only applies to
+// generated tables, and setting both is an error.
+func WithTable(table [256]uint64) Option {
+	return func(c *config) error {
+		if table == ([256]uint64{}) {
+			return ErrInvalidTable
+		}
+
+		c.table = &table
+
+		return nil
+	}
+}
+
+// WithFixedSize disables content-defined chunking: every chunk but the last
+// has exactly size bytes, whatever its content, so that fixed-size and
+// content-defined chunking can be compared through the same API. Boundaries
+// only depend on offsets: the same input always chunks the same way, but an
+// insertion shifts every later boundary, which is what content-defined
+// chunking avoids. Chunk.Hash is always zero since no fingerprint is computed,
+// and every cut counts as forced at maxSize, which equals size.
+//
+// The size replaces WithMinSize, WithTargetSize and WithMaxSize. Options only
+// meaningful for content-defined chunking, such as WithNormalization,
+// WithMasks or WithAdaptiveTarget, return ErrFixedSizeConflict.
+func WithFixedSize(size uint32) Option {
+	return func(c *config) error {
+		if size == 0 {
+			return ErrInvalidFixedSize
+		}
+
+		c.fixedSize = size
+
+		return nil
+	}
+}
+
+// WithHashFromStart makes the Gear hash accumulate the fingerprint over the
+// bytes before minSize instead of skipping them, without testing the masks
+// there, as FastCDC descriptions that hash every byte do. Chunk.Hash and the
+// boundaries then match such implementations: only the 64 bytes before a
+// boundary affect a Gear fingerprint, so they differ from the default in the
+// first 64 bytes after minSize. Only those 64 bytes are hashed for it, so the
+// cost is 64 table lookups per chunk, within measurement noise at the default
+// sizes but noticeable for chunks of a few hundred bytes. HashRabin already
+// hashes its window before minSize and is unaffected.
+func WithHashFromStart() Option {
+	return func(c *config) error {
+		c.hashFromStart = true
+
+		return nil
+	}
+}
+
+// WithUnroll selects the Gear hash loop of FindBoundary: the 8x unrolled loop
+// (default), which also hashes long regions in parallel lanes where supported,
+// or a plain loop with less code per call. Boundaries are identical either way.
+//
+// The unrolled loop is measured as fast or faster for every maxSize from 256
+// bytes up on amd64 (BenchmarkChunkerCoreUnroll), so it stays the default even
+// for tiny chunks; disable it only if that benchmark favors the plain loop on
+// your hardware. Other rolling hashes and WithNormRegions are unaffected.
+func WithUnroll(enabled bool) Option {
+	return func(c *config) error {
+		c.noUnroll = !enabled
+
+		return nil
+	}
+}
+
 // WithBufferSize sets the internal buffer size for the streaming API.
-// Must be at least as large as maxSize.
+// A buffer that cannot hold more than maxSize bytes is replaced with one of
+// RecommendedBufferSize, unless WithPartialChunks is set.
 func WithBufferSize(size int) Option {
 	return func(c *config) error {
 		if size <= 0 {
@@ -192,3 +968,270 @@ func WithBufferSize(size int) Option {
 		return nil
 	}
 }
+
+// WithPartialChunks lets the streaming API use an internal buffer smaller than
+// maxSize, for very large chunks such as a 16 MiB target and 64 MiB maxSize
+// for archival dedup, whose whole-chunk buffer would otherwise be several
+// times maxSize (see RecommendedBufferSize). The buffer set with WithBufferSize
+// or WithBuffer is used as is, and must hold at least 2 bytes.
+//
+// A chunk that does not fit in the buffer is returned by several calls to
+// Next, each with Chunk.Partial set but the last: the parts are contiguous,
+// with their own Offset, Length and Data, and the fingerprint state carries
+// over between them, so boundaries are the same as without the option. Hash
+// and Digest are only set on the final part, and Last on the final part of
+// the final chunk. Chunks that fit are returned whole, as usual. A
+// *bufio.Reader is only read from directly if it can hold a maximum-size
+// chunk.
+//
+// WithWideFingerprint, WithPreferredDelimiter, WithMinChunkCount, TailMerge
+// and NewFrameAwareChunker need whole chunks in the buffer and cannot be
+// combined with it: validation returns ErrPartialChunksConflict.
+func WithPartialChunks() Option {
+	return func(c *config) error {
+		c.partial = true
+
+		return nil
+	}
+}
+
+// WithBuffer makes the streaming API use buf as its internal buffer instead of
+// allocating one, so that servers can pool buffers across chunkers. buf must
+// hold more than maxSize bytes (maxSize+minSize with TailMerge), or 2 bytes
+// with WithPartialChunks, otherwise ErrBufferTooSmall is returned; its length
+// overrides WithBufferSize. The chunker owns buf until it is no longer used,
+// and the data of returned chunks points into it. Since each chunker needs its
+// own buffer, NewConfig and NewChunkerPool return ErrSharedBuffer; pass it to
+// NewChunker or Reconfigure.
+func WithBuffer(buf []byte) Option {
+	return func(c *config) error {
+		c.buffer = slices.Clip(buf)
+
+		return nil
+	}
+}
+
+// WithGuardedData makes Chunker.Next return chunk data through Chunk.Guarded
+// instead of Chunk.Data. Accessing the guarded data after a subsequent call to
+// Next or Reset panics instead of silently returning overwritten bytes.
+// The check is a single counter comparison.
+func WithGuardedData(enabled bool) Option {
+	return func(c *config) error {
+		c.guardedData = enabled
+
+		return nil
+	}
+}
+
+// WithCopyData makes Chunker.Next copy each chunk's data into a newly allocated
+// slice, so Chunk.Data stays valid after subsequent calls and can be retained.
+// This costs one allocation and a copy of every chunk; prefer the default
+// zero-copy behavior when chunks are processed before calling Next again.
+func WithCopyData() Option {
+	return func(c *config) error {
+		c.copyData = true
+
+		return nil
+	}
+}
+
+// WithoutHash declares that only chunk boundaries are needed: Chunk.Hash is
+// left zero and no per-chunk hashing beyond boundary detection is done, now or
+// as features are added. It cannot be combined with WithChunkDigest or
+// WithChunkID. The ChunkerCore API is unaffected.
+func WithoutHash() Option {
+	return func(c *config) error {
+		c.noHash = true
+
+		return nil
+	}
+}
+
+// WithAbsoluteOffsets makes a chunker created by NewRangeChunker report Chunk.Offset
+// and Offset as positions in the underlying io.ReaderAt rather than relative to
+// the start of the range. Other chunkers start at offset 0 and are unaffected.
+func WithAbsoluteOffsets() Option {
+	return func(c *config) error {
+		c.absoluteOffsets = true
+
+		return nil
+	}
+}
+
+// WithAdaptiveTarget makes the streaming API adjust its masks as it goes to keep
+// the average chunk size near targetSize on data whose entropy varies. After
+// every 64 chunks, if their average size is more than 25% above targetSize a
+// mask bit is removed, doubling the cut probability, and if it is more than
+// 25% below one is added. minSize and maxSize still bound every chunk. The
+// masks start over from the configured ones on Reset.
+//
+// Adaptive chunking is not deterministic in the content: where a chunk ends
+// depends on the chunks before it, so the same data chunks differently after
+// a different prefix and boundaries do not re-synchronize after an edit. Use
+// it for size consistency, such as smoothing bandwidth in a live stream, and
+// never for content addressing or deduplication. The ChunkerCore API is
+// unaffected.
+func WithAdaptiveTarget() Option {
+	return func(c *config) error {
+		c.adaptive = true
+
+		return nil
+	}
+}
+
+// WithWideFingerprint makes the streaming API populate Chunk.Hash128, a 128-bit
+// fingerprint for use as a cheap dedup key with far fewer collisions than
+// Chunk.Hash alone. The low lane, Hash128[0], is Chunk.Hash, which boundary
+// detection uses as before; it only depends on the last 64 bytes of the chunk.
+// The high lane, Hash128[1], is a multiplicative hash of every byte of the
+// chunk with its own table, computed in a second pass over the chunk.
+//
+// Hash128 is not a cryptographic hash: crafted inputs can collide, so use
+// WithChunkDigest when the data is untrusted. It cannot be combined with
+// WithoutHash.
+func WithWideFingerprint() Option {
+	return func(c *config) error {
+		c.wide = true
+
+		return nil
+	}
+}
+
+// WithMinChunkCount splits small inputs into at least n chunks where possible, so
+// that files not much larger than minSize still deduplicate.
+//
+// It applies to the streaming API when the whole input is shorter than maxSize.
+// If natural chunking produces fewer than n chunks, minSize, targetSize and maxSize
+// are halved, lowering the masks accordingly, until there are n chunks or minSize
+// would drop below 64 bytes. Boundaries stay content-defined, so inputs of similar
+// size split at the same positions. minSize is therefore no longer a lower bound on
+// chunk sizes for such inputs. Larger inputs are chunked as usual.
+func WithMinChunkCount(n int) Option {
+	return func(c *config) error {
+		if n < 1 {
+			return fmt.Errorf("%w: got %d", ErrInvalidMinChunkCount, n)
+		}
+
+		c.minChunks = n
+
+		return nil
+	}
+}
+
+// WithChunkID makes Chunker.Next populate Chunk.ID with the SHA-256 of namespace
+// followed by the chunk data, a content address that services sharing the
+// namespace agree on and that differs between namespaces, such as tenants or
+// stores. It costs a SHA-256 pass and a 32-byte allocation per chunk, in
+// addition to WithChunkDigest if both are set. The namespace may be empty, and
+// is copied. It cannot be combined with WithoutHash.
+func WithChunkID(namespace []byte) Option {
+	return func(c *config) error {
+		c.chunkID = append([]byte{}, namespace...)
+
+		return nil
+	}
+}
+
+// WithChunkDigest sets the cryptographic hash used for chunk content digests,
+// such as sha256.New. When set, Chunker.Next populates Chunk.Digest, which unlike
+// the Gear fingerprint in Chunk.Hash is suitable as a dedup key.
+// ReconstructVerified uses it to verify stored chunks and defaults to SHA-256
+// when it is not set.
+func WithChunkDigest(h func() hash.Hash) Option {
+	return func(c *config) error {
+		c.digest = h
+
+		return nil
+	}
+}
+
+// WithNormalizationStrength sets how many fewer bits the small mask has than the
+// large mask (default 1). Each extra bit doubles the cut probability in the
+// normalized region [minSize, normSize), so larger values pull more boundaries
+// into that region and tighten the distribution around it, at the cost of more
+// chunks close to normSize. WithNormalization controls the width of the region;
+// this controls how aggressively it cuts. Must be less than the number of bits
+// in targetSize.
+func WithNormalizationStrength(delta uint8) Option {
+	return func(c *config) error {
+		c.normStrength = delta
+
+		return nil
+	}
+}
+
+// WithStats enables collection of chunk size statistics, available from
+// Chunker.Stats. The statistics are updated in O(1) per chunk.
+func WithStats(enabled bool) Option {
+	return func(c *config) error {
+		c.stats = enabled
+
+		return nil
+	}
+}
+
+// WithProgress sets a callback reporting how many bytes of the stream have been
+// chunked, for progress bars. It is called from Chunker.Next, and so from
+// Process and All, on the goroutine calling it: after the first chunk, then
+// whenever at least 1 MiB more has been chunked, and after the last chunk, with
+// the total length. The count restarts at 0 on Reset and does not include the
+// range start of WithAbsoluteOffsets. fn must not call back into the chunker.
+// A nil fn disables the callback.
+func WithProgress(fn func(bytesProcessed uint64)) Option {
+	return func(c *config) error {
+		c.progress = fn
+
+		return nil
+	}
+}
+
+// WithTee makes the streaming API write the data of every chunk to w, in
+// order, as Next returns it, so that each byte of the stream is written
+// exactly once, for example to a whole-file SHA-256 computed in the same pass.
+// A final chunk rejected by TailError is not written. If w fails, or writes
+// less than a chunk, Next returns the error and all later calls fail with it
+// until Reset, since w would otherwise miss data. A Config with a tee writes to
+// the same w from every chunker created from it.
+func WithTee(w io.Writer) Option {
+	return func(c *config) error {
+		c.tee = w
+
+		return nil
+	}
+}
+
+// WithRetry makes the streaming API retry a failed read up to n times before
+// returning the *ReadError, for readers with transient failures such as
+// object storage. It requires a reader implementing io.Seeker, such as an
+// *os.File or an *io.SectionReader: before each retry the reader is rewound to
+// just after the last byte read successfully, relative to its position when
+// the chunker was created or Reset. For other readers, including a
+// *bufio.Reader, it does nothing, and Next can still be called again after an
+// error to resume. n = 0 disables retries.
+func WithRetry(n int) Option {
+	return func(c *config) error {
+		if n < 0 {
+			return fmt.Errorf("%w: got %d", ErrInvalidRetryCount, n)
+		}
+
+		c.retries = n
+
+		return nil
+	}
+}
+
+// WithMaxBufferSize sets the ceiling for the internal buffer of the streaming API
+// (default 64 MiB). NewChunker returns ErrBufferTooLarge if the buffer size, which
+// is raised to at least maxSize, exceeds it. This protects services that build
+// chunkers from untrusted configuration against huge allocations.
+func WithMaxBufferSize(size int) Option {
+	return func(c *config) error {
+		if size <= 0 {
+			return ErrInvalidBufferSize
+		}
+
+		c.maxBuffer = size
+
+		return nil
+	}
+}
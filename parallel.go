@@ -0,0 +1,238 @@
+package fastcdc
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"hash"
+	"os"
+	"runtime"
+	"slices"
+	"sync"
+)
+
+// minParallelSegment is the minimum segment size per worker, in multiples of maxSize.
+// Smaller segments spend most of their time re-synchronizing.
+const minParallelSegment = 16
+
+// cut is a chunk end offset and the fingerprint at the boundary.
+type cut struct {
+	end  int
+	hash uint64
+}
+
+// ChunkFileParallel chunks the file at path using multiple goroutines and returns
+// exactly the chunks that sequential chunking would produce.
+//
+// The file is split into one segment per worker. Each worker chunks its segment
+// from a fresh state, continuing past the end of the segment until its first cut
+// beyond it. Because a boundary only depends on the data since the previous
+// boundary, a worker's chunks match the sequential ones from the first cut the two
+// have in common. The segments are then stitched together, chunking sequentially
+// from the end of the previous segment until that shared cut is found, which
+// usually takes a few chunks.
+//
+// The whole file is read into memory and each Chunk.Data is a sub-slice of it.
+// If workers <= 0, GOMAXPROCS workers are used. The number of workers is reduced
+// for small files.
+//
+// WithoutHash, WithWideFingerprint, WithChunkDigest and WithChunkID are applied
+// to the stitched chunks. Options that move or merge boundaries after the core
+// finds them (TailMerge, TailError, WithPreferredDelimiter, WithMinChunkCount
+// and WithAdaptiveTarget), WithGuardedData, WithTee and WithProgress return
+// ErrUnsupportedOption.
+func ChunkFileParallel(path string, workers int, opts ...Option) ([]Chunk, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	if option := cfg.parallelConflict(); option != "" {
+		return nil, fmt.Errorf("%w: %s with ChunkFileParallel", ErrUnsupportedOption, option)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	core := newChunkerCoreWithConfig(&cfg)
+
+	if workers <= 0 {
+		workers = runtime.GOMAXPROCS(0)
+	}
+
+	workers = min(workers, len(data)/(minParallelSegment*int(core.MaxSize())))
+	workers = max(workers, 1)
+
+	// Phase 1: chunk every segment independently
+	segments := make([][]cut, workers)
+
+	var wg sync.WaitGroup
+
+	for i := range workers {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			worker := core // Each worker needs its own rolling state
+			start := i * len(data) / workers
+			end := (i + 1) * len(data) / workers
+			segments[i] = chunkRange(&worker, data, start, end)
+		}()
+	}
+
+	wg.Wait()
+
+	// Phase 2: stitch the segments, re-synchronizing at each segment start
+	var cuts []cut
+
+	pos := 0
+
+	for _, segment := range segments {
+		for pos < len(data) {
+			idx, ok := slices.BinarySearchFunc(segment, pos, func(c cut, end int) int {
+				return c.end - end
+			})
+			if ok {
+				// pos is a cut of this segment too: all its following cuts are sequential cuts
+				cuts = append(cuts, segment[idx+1:]...)
+				pos = cuts[len(cuts)-1].end
+
+				break
+			}
+
+			if len(segment) == 0 || pos > segment[len(segment)-1].end {
+				// Passed this segment without synchronizing, try the next one
+				break
+			}
+
+			next := nextCut(&core, data, pos)
+			cuts = append(cuts, next)
+			pos = next.end
+		}
+	}
+
+	// Chunk whatever no segment covered (only when synchronization failed at the end)
+	for pos < len(data) {
+		next := nextCut(&core, data, pos)
+		cuts = append(cuts, next)
+		pos = next.end
+	}
+
+	return parallelChunks(&cfg, data, cuts), nil
+}
+
+// parallelConflict returns the first option that ChunkFileParallel cannot
+// apply, or "" if there is none.
+func (c *config) parallelConflict() string {
+	switch {
+	case c.tail == TailMerge:
+		return "TailMerge"
+	case c.tail == TailError:
+		return "TailError"
+	case c.delimWindow != 0:
+		return "WithPreferredDelimiter"
+	case c.minChunks > 1:
+		return "WithMinChunkCount"
+	case c.adaptive:
+		return "WithAdaptiveTarget"
+	case c.guardedData:
+		return "WithGuardedData"
+	case c.tee != nil:
+		return "WithTee"
+	case c.progress != nil:
+		return "WithProgress"
+	}
+
+	return ""
+}
+
+// parallelChunks returns the chunks of data ending at cuts, with the per-chunk
+// options of cfg applied as Chunker.Next does.
+func parallelChunks(cfg *config, data []byte, cuts []cut) []Chunk {
+	var digest, idHash hash.Hash
+	if cfg.digest != nil {
+		digest = cfg.digest()
+	}
+
+	if cfg.chunkID != nil {
+		idHash = sha256.New()
+	}
+
+	chunks := make([]Chunk, len(cuts))
+	start := 0
+
+	for i, c := range cuts {
+		chunk := Chunk{
+			Offset: uint64(start),         //nolint:gosec // G115
+			Length: uint32(c.end - start), //nolint:gosec // G115
+			Hash:   c.hash,
+			Data:   data[start:c.end],
+			Last:   i == len(cuts)-1,
+		}
+
+		if cfg.noHash {
+			chunk.Hash = 0
+		}
+
+		if cfg.wide {
+			chunk.Hash128 = [2]uint64{chunk.Hash, wideLane(chunk.Data)}
+		}
+
+		if digest != nil {
+			digest.Reset()
+			digest.Write(chunk.Data)
+			chunk.Digest = digest.Sum(nil)
+		}
+
+		if idHash != nil {
+			idHash.Reset()
+			idHash.Write(cfg.chunkID)
+			idHash.Write(chunk.Data)
+			chunk.ID = idHash.Sum(nil)
+		}
+
+		chunks[i] = chunk
+		start = c.end
+	}
+
+	return chunks
+}
+
+// chunkRange chunks data from start, returning the cuts up to and including
+// the first cut at or beyond end.
+func chunkRange(core *ChunkerCore, data []byte, start, end int) []cut {
+	var cuts []cut
+
+	for pos := start; pos < len(data); {
+		next := nextCut(core, data, pos)
+		cuts = append(cuts, next)
+		pos = next.end
+
+		if pos >= end {
+			break
+		}
+	}
+
+	return cuts
+}
+
+// nextCut returns the end of the chunk starting at pos, treating the end of
+// data as the end of the stream.
+func nextCut(core *ChunkerCore, data []byte, pos int) cut {
+	core.Reset()
+
+	boundary, hash, found := core.FindBoundary(data[pos:])
+	if !found {
+		boundary = len(data) - pos
+	}
+
+	return cut{end: pos + boundary, hash: hash}
+}
@@ -0,0 +1,145 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestChunkFileParallel verifies that parallel chunking matches sequential chunking.
+func TestChunkFileParallel(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 8*1024*1024+12345)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(2 * 1024),
+		fastcdc.WithTargetSize(8 * 1024),
+		fastcdc.WithMaxSize(32 * 1024),
+	}
+
+	sequential, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []fastcdc.Chunk
+
+	for chunk, err := range sequential.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want = append(want, chunk)
+	}
+
+	for _, workers := range []int{1, 3, 8, 0} {
+		got, err := fastcdc.ChunkFileParallel(path, workers, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("workers=%d: %d chunks, want %d", workers, len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i].Offset != want[i].Offset || got[i].Length != want[i].Length || got[i].Hash != want[i].Hash {
+				t.Fatalf("workers=%d: chunk %d mismatch: %+v vs %+v", workers, i, got[i], want[i])
+			}
+
+			if !bytes.Equal(got[i].Data, data[want[i].Offset:want[i].Offset+uint64(want[i].Length)]) {
+				t.Fatalf("workers=%d: chunk %d data mismatch", workers, i)
+			}
+		}
+	}
+
+	if _, err := fastcdc.ChunkFileParallel(filepath.Join(t.TempDir(), "missing"), 2); !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("Expected not-exist error, got %v", err)
+	}
+}
+
+// TestChunkFileParallelOptions verifies that the per-chunk options give the
+// same chunks as sequential chunking, and that options ChunkFileParallel cannot
+// apply are rejected.
+func TestChunkFileParallelOptions(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024+777, 1259)
+
+	path := filepath.Join(t.TempDir(), "data")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(2 * 1024),
+		fastcdc.WithTargetSize(8 * 1024),
+		fastcdc.WithMaxSize(32 * 1024),
+		fastcdc.WithChunkDigest(sha256.New),
+		fastcdc.WithChunkID([]byte("namespace")),
+		fastcdc.WithWideFingerprint(),
+	}
+
+	sequential := mustChunker(t, bytes.NewReader(data), opts...)
+
+	var want []fastcdc.Chunk
+
+	for chunk, err := range sequential.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		want = append(want, chunk)
+	}
+
+	got, err := fastcdc.ChunkFileParallel(path, 4, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(got) != len(want) {
+		t.Fatalf("%d chunks, want %d", len(got), len(want))
+	}
+
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.Offset != w.Offset || g.Length != w.Length || g.Hash128 != w.Hash128 || g.Last != w.Last ||
+			!bytes.Equal(g.Digest, w.Digest) || !bytes.Equal(g.ID, w.ID) {
+			t.Fatalf("Chunk %d mismatch: %+v vs %+v", i, g, w)
+		}
+	}
+
+	unsupported := map[string]fastcdc.Option{
+		"TailMerge":              fastcdc.WithTailPolicy(fastcdc.TailMerge),
+		"TailError":              fastcdc.WithTailPolicy(fastcdc.TailError),
+		"WithPreferredDelimiter": fastcdc.WithPreferredDelimiter('\n', 64),
+		"WithMinChunkCount":      fastcdc.WithMinChunkCount(4),
+		"WithAdaptiveTarget":     fastcdc.WithAdaptiveTarget(),
+		"WithGuardedData":        fastcdc.WithGuardedData(true),
+		"WithTee":                fastcdc.WithTee(io.Discard),
+		"WithProgress":           fastcdc.WithProgress(func(uint64) {}),
+	}
+
+	for name, opt := range unsupported {
+		if _, err := fastcdc.ChunkFileParallel(path, 2, opt); !errors.Is(err, fastcdc.ErrUnsupportedOption) {
+			t.Errorf("%s: expected ErrUnsupportedOption, got %v", name, err)
+		}
+	}
+}
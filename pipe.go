@@ -0,0 +1,124 @@
+package fastcdc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// pipeBatchSize is the maximum number of chunks sent per batch by Pipe.
+const pipeBatchSize = 64
+
+// Pipe chunks the stream in a new goroutine, decoupling the data path from the
+// metadata path. The returned pipe reader yields the original bytes unchanged,
+// while the channel delivers the chunk metadata in batches, in stream order.
+// Chunk.Data is nil in the batches, since the data is delivered through the pipe,
+// and so is Chunk.Guarded with WithGuardedData.
+//
+// Both the pipe and the channel must be consumed concurrently: the goroutine
+// blocks until each chunk has been read from the pipe and each batch received.
+// The channel is closed when chunking stops. A read error is returned by the pipe
+// reader after the data of the chunks before it, whose batches are all sent;
+// closing the pipe reader early stops chunking. The Chunker must not be
+// used by the caller until the channel is closed.
+func (c *Chunker) Pipe() (*io.PipeReader, <-chan []Chunk) {
+	pr, pw := io.Pipe()
+	batches := make(chan []Chunk, 1)
+
+	go func() {
+		defer close(batches)
+
+		batch := make([]Chunk, 0, pipeBatchSize)
+
+		var readErr error
+
+		for {
+			chunk, err := c.Next()
+			if errors.Is(err, io.EOF) {
+				break
+			}
+
+			if err != nil {
+				readErr = err
+
+				break
+			}
+
+			data := chunk.Data
+			if c.cfg.guardedData {
+				data, chunk.Guarded = chunk.Guarded.Bytes(), ChunkData{}
+			}
+
+			if _, err := pw.Write(data); err != nil {
+				// The reader side was closed
+				return
+			}
+
+			chunk.Data = nil
+			batch = append(batch, chunk)
+
+			if len(batch) == pipeBatchSize {
+				batches <- batch
+				batch = make([]Chunk, 0, pipeBatchSize)
+			}
+		}
+
+		// The chunks written before a read error are sent before it is returned
+		if len(batch) > 0 {
+			batches <- batch
+		}
+
+		pw.CloseWithError(readErr) // Close with a nil error at EOF
+	}()
+
+	return pr, batches
+}
+
+// Channel chunks the stream in a new goroutine and sends the chunks to the
+// returned channel, which has a capacity of bufferSize chunks, so that chunking
+// overlaps with their processing. The chunk channel is closed when chunking
+// stops. The error channel then yields the read error that stopped it, if any,
+// and is closed; at EOF it is closed without a value, so receiving from it
+// after the chunk channel is drained returns nil on success.
+//
+// Since the internal buffer is overwritten while earlier chunks are still in
+// the channel, every Chunk.Data sent is a copy owned by the receiver, whether
+// or not WithCopyData is set. With WithGuardedData the copy is in Data and
+// Guarded is left empty.
+//
+// The chunk channel must be drained: the goroutine blocks until each chunk is
+// received. The Chunker must not be used by the caller until the chunk channel
+// is closed.
+func (c *Chunker) Channel(bufferSize int) (<-chan Chunk, <-chan error) {
+	chunks := make(chan Chunk, max(bufferSize, 0))
+	errs := make(chan error, 1)
+
+	go func() {
+		defer close(errs)
+		defer close(chunks)
+
+		for {
+			chunk, err := c.Next()
+			if errors.Is(err, io.EOF) {
+				return
+			}
+
+			if err != nil {
+				errs <- err
+
+				return
+			}
+
+			switch {
+			case c.cfg.guardedData:
+				chunk.Data, chunk.Guarded = bytes.Clone(chunk.Guarded.Bytes()), ChunkData{}
+			case !c.cfg.copyData:
+				chunk.Data = bytes.Clone(chunk.Data)
+			}
+
+			chunks <- chunk
+		}
+	}()
+
+	return chunks, errs
+}
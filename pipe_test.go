@@ -0,0 +1,250 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+//nolint:gochecknoglobals
+var errTestRead = errors.New("test read error")
+
+// errAfterReader returns data and then a non-EOF error.
+type errAfterReader struct {
+	r   io.Reader
+	err error
+}
+
+func (e *errAfterReader) Read(p []byte) (int, error) {
+	n, err := e.r.Read(p)
+	if errors.Is(err, io.EOF) {
+		return n, e.err
+	}
+
+	return n, err
+}
+
+// TestChunkerPipe verifies that the pipe reproduces the input and the
+// metadata matches direct chunking.
+func TestChunkerPipe(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 4*1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(32 * 1024)}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, batches := chunker.Pipe()
+
+	var got []fastcdc.Chunk
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for batch := range batches {
+			got = append(got, batch...)
+		}
+	}()
+
+	out, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-done
+
+	if !bytes.Equal(out, data) {
+		t.Error("Pipe output does not match input")
+	}
+
+	direct, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; ; i++ {
+		want, err := direct.Next()
+		if errors.Is(err, io.EOF) {
+			if i != len(got) {
+				t.Errorf("Pipe produced %d chunks, want %d", len(got), i)
+			}
+
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if i >= len(got) {
+			t.Fatalf("Pipe produced only %d chunks", len(got))
+		}
+
+		if got[i].Offset != want.Offset || got[i].Length != want.Length || got[i].Hash != want.Hash {
+			t.Errorf("Chunk %d mismatch: %+v vs %+v", i, got[i], want)
+		}
+	}
+}
+
+// TestChunkerPipeGuarded verifies that the pipe carries the data of guarded chunks.
+func TestChunkerPipeGuarded(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(300000, 1228)
+	pr, batches := mustChunker(t, bytes.NewReader(data), fastcdc.WithMinSize(4*1024), fastcdc.WithTargetSize(16*1024),
+		fastcdc.WithGuardedData(true)).Pipe()
+
+	var chunks int
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for batch := range batches {
+			chunks += len(batch)
+		}
+	}()
+
+	out, err := io.ReadAll(pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	<-done
+
+	if !bytes.Equal(out, data) {
+		t.Errorf("Pipe output has %d bytes, want the %d of the input", len(out), len(data))
+	}
+
+	if chunks == 0 {
+		t.Error("Pipe produced no chunks")
+	}
+}
+
+// TestChunkerPipeError verifies that read errors propagate to the pipe reader,
+// after the data and metadata of every chunk before them.
+func TestChunkerPipeError(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1228)
+
+	chunker, err := fastcdc.NewChunker(&errAfterReader{r: bytes.NewReader(data), err: errTestRead},
+		fastcdc.WithMinSize(2*1024), fastcdc.WithTargetSize(8*1024), fastcdc.WithMaxSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pr, batches := chunker.Pipe()
+
+	var length uint64
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		for batch := range batches {
+			for _, chunk := range batch {
+				length += uint64(chunk.Length)
+			}
+		}
+	}()
+
+	out, err := io.ReadAll(pr)
+	if !errors.Is(err, errTestRead) {
+		t.Errorf("Expected read error to propagate, got %v", err)
+	}
+
+	<-done
+
+	if len(out) == 0 || uint64(len(out)) != length {
+		t.Errorf("Pipe yielded %d bytes before the error and chunks of %d bytes", len(out), length)
+	}
+}
+
+// TestChunkerChannel verifies that the chunks received from the channel keep
+// their data and match direct chunking, and that read errors are delivered.
+func TestChunkerChannel(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(4*1024*1024, 1336)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(32 * 1024)}
+
+	var want []fastcdc.Chunk
+
+	c := mustChunker(t, bytes.NewReader(data), append(opts, fastcdc.WithCopyData())...)
+
+	err := c.Process(func(chunk fastcdc.Chunk) error {
+		want = append(want, chunk)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, extra := range map[string][]fastcdc.Option{
+		"default":   nil,
+		"copy data": {fastcdc.WithCopyData()},
+		"guarded":   {fastcdc.WithGuardedData(true)},
+	} {
+		chunks, errs := mustChunker(t, bytes.NewReader(data), append(opts, extra...)...).Channel(4)
+
+		var got []fastcdc.Chunk
+
+		// The data of every chunk is checked only once all are received
+		for chunk := range chunks {
+			if chunk.Guarded.Valid() {
+				t.Fatalf("%s: chunk at %d sent with guarded data", name, chunk.Offset)
+			}
+
+			got = append(got, chunk)
+		}
+
+		if err := <-errs; err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+
+		if !slices.EqualFunc(got, want, func(a, b fastcdc.Chunk) bool {
+			return a.Offset == b.Offset && a.Hash == b.Hash && a.Last == b.Last && bytes.Equal(a.Data, b.Data)
+		}) {
+			t.Errorf("%s: channel chunks differ from direct chunking", name)
+		}
+	}
+
+	// A read error ends the chunks and is sent on the error channel
+	r := &errAfterReader{r: bytes.NewReader(data[:1024*1024]), err: errTestRead}
+	chunks, errs := mustChunker(t, r, opts...).Channel(0)
+
+	var received int
+	for range chunks {
+		received++
+	}
+
+	if received == 0 {
+		t.Error("no chunks before the read error")
+	}
+
+	if err := <-errs; !errors.Is(err, errTestRead) {
+		t.Errorf("got %v, want the read error", err)
+	}
+
+	if _, ok := <-errs; ok {
+		t.Error("error channel not closed after the error")
+	}
+}
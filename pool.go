@@ -1,28 +1,36 @@
 package fastcdc
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"sync"
+	"sync/atomic"
 )
 
+// ErrInvalidShardCount is returned by NewShardedChunkerPool when shards is less than 1.
+var ErrInvalidShardCount = errors.New("shard count must be at least 1")
+
 // ChunkerPool is a pool of Chunker instances for reuse in high-throughput scenarios.
 // It reduces allocations by recycling chunkers instead of creating new ones.
+// The memory it retains is bounded by the configured buffer size: Put drops
+// chunkers whose buffer has grown past it.
 type ChunkerPool struct {
 	pool sync.Pool
-	opts []Option
+	cfg  Config
 }
 
 // NewChunkerPool creates a new ChunkerPool with the given options.
 // All chunkers created from this pool will use these options.
 func NewChunkerPool(opts ...Option) (*ChunkerPool, error) {
-	// Validate options by creating a test chunker
-	_, err := NewChunker(nil, opts...)
+	// Validate options once; chunkers are then created from the validated config
+	cfg, err := NewConfig(opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	return &ChunkerPool{
-		opts: opts,
+		cfg: cfg,
 	}, nil
 }
 
@@ -36,17 +44,109 @@ func (p *ChunkerPool) Get(r io.Reader) (*Chunker, error) {
 		return chunker, nil
 	}
 
-	return NewChunker(r, p.opts...)
+	return NewChunkerFromConfig(r, p.cfg), nil
+}
+
+// Warm creates n chunkers, each with its internal buffer allocated, and puts
+// them in the pool so that the first calls to Get do not allocate. Like any
+// pooled value, warmed chunkers may be dropped by the garbage collector.
+func (p *ChunkerPool) Warm(n int) {
+	for range n {
+		p.Put(NewChunkerFromConfig(nil, p.cfg))
+	}
 }
 
 // Put returns a Chunker to the pool for reuse.
 // The chunker should not be used after being returned to the pool.
+//
+// A chunker whose internal buffer is larger than the pool's buffer size, after
+// Reconfigure with a larger WithBufferSize or maxSize, is not kept: it is left
+// to the garbage collector and a later Get allocates one with the pool's
+// options, so that a long-running pool never holds more than one buffer of the
+// configured size per chunker.
 func (p *ChunkerPool) Put(c *Chunker) {
+	if cap(c.buf) > p.cfg.cfg.bufferSize && !c.external {
+		return
+	}
+
 	// Clear the reader to avoid holding references
 	c.reader = nil
+	c.br = nil
+	c.sources = nil
 	p.pool.Put(c)
 }
 
+// ShardedChunkerPool is a ChunkerPool split into shards that Get takes from in
+// turn, each chunker going back to its own shard on Put, to spread contention
+// on a pool shared by many goroutines.
+//
+// Go offers no way to find the CPU or NUMA node of a goroutine, so shards are
+// not tied to either. sync.Pool already keeps a cache per P (GOMAXPROCS slot)
+// without locking, which removes most contention: measure with
+// BenchmarkChunkerPoolParallel before preferring this over ChunkerPool. A
+// chunker taken from a shard whose cache on the current P is empty is
+// allocated even if other shards hold spare ones, so more shards can mean
+// more allocations.
+type ShardedChunkerPool struct {
+	shards []ChunkerPool
+	next   atomic.Uint32 // Shard of the next Get
+}
+
+// NewShardedChunkerPool creates a pool of shards ChunkerPools using opts, which
+// are validated once. It returns ErrInvalidShardCount if shards is less than 1.
+func NewShardedChunkerPool(shards int, opts ...Option) (*ShardedChunkerPool, error) {
+	if shards < 1 {
+		return nil, fmt.Errorf("%w: got %d", ErrInvalidShardCount, shards)
+	}
+
+	cfg, err := NewConfig(opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &ShardedChunkerPool{shards: make([]ChunkerPool, shards)}
+	for i := range p.shards {
+		p.shards[i].cfg = cfg
+	}
+
+	return p, nil
+}
+
+// Get retrieves a Chunker from the next shard, or creates a new one if that
+// shard is empty. The chunker is configured with the given reader and ready to use.
+func (p *ShardedChunkerPool) Get(r io.Reader) (*Chunker, error) {
+	shard := int(p.next.Add(1) % uint32(len(p.shards))) //nolint:gosec // G115
+
+	c, err := p.shards[shard].Get(r)
+	if err != nil {
+		return nil, err
+	}
+
+	c.shard = shard
+
+	return c, nil
+}
+
+// Warm creates n chunkers, as ChunkerPool.Warm does, spread over the shards.
+func (p *ShardedChunkerPool) Warm(n int) {
+	for i := range n {
+		c := NewChunkerFromConfig(nil, p.shards[0].cfg)
+		c.shard = i % len(p.shards)
+		p.Put(c)
+	}
+}
+
+// Put returns a Chunker to the shard it was taken from for reuse. Chunkers not
+// taken from this pool go to the first shard.
+// The chunker should not be used after being returned to the pool.
+func (p *ShardedChunkerPool) Put(c *Chunker) {
+	if c.shard >= len(p.shards) {
+		c.shard = 0
+	}
+
+	p.shards[c.shard].Put(c)
+}
+
 // ChunkerCorePool is a pool of ChunkerCore instances for reuse.
 type ChunkerCorePool struct {
 	pool sync.Pool
@@ -0,0 +1,126 @@
+package fastcdc
+
+import (
+	"math/bits"
+	"sync"
+)
+
+const (
+	// RabinPolynomial is the irreducible polynomial of degree 53 used by HashRabin.
+	// It is the polynomial of restic's chunker tests.
+	RabinPolynomial = 0x3DA3358B4DC173
+
+	// rabinWindowSize is the number of bytes a Rabin fingerprint covers.
+	rabinWindowSize = 64
+
+	// rabinShift extracts the top byte of a fingerprint of degree below 53.
+	rabinShift = 53 - 8
+)
+
+// rabinTables holds the precomputed tables for RabinPolynomial.
+type rabinTables struct {
+	out [256]uint64 // Contribution of a byte leaving the window
+	mod [256]uint64 // Reduction of the byte shifted out of the top
+}
+
+// rabinTablesFor computes the tables once, on first use of HashRabin.
+//
+//nolint:gochecknoglobals
+var rabinTablesFor = sync.OnceValue(func() *rabinTables {
+	var t rabinTables
+
+	for b := range 256 {
+		h := rabinAppend(0, byte(b))
+		for range rabinWindowSize - 1 {
+			h = rabinAppend(h, 0)
+		}
+
+		t.out[b] = h
+		t.mod[b] = polyMod(uint64(b)<<53, RabinPolynomial) | uint64(b)<<53
+	}
+
+	return &t
+})
+
+// rabinAppend appends byte b to fingerprint h, reducing modulo RabinPolynomial.
+func rabinAppend(h uint64, b byte) uint64 {
+	return polyMod(h<<8|uint64(b), RabinPolynomial)
+}
+
+// polyMod returns x modulo d as polynomials over GF(2).
+func polyMod(x, d uint64) uint64 {
+	dd := bits.Len64(d)
+	for bits.Len64(x) >= dd {
+		x ^= d << (bits.Len64(x) - dd)
+	}
+
+	return x
+}
+
+// rabinWindow is the rolling state of a Rabin fingerprint over the last
+// rabinWindowSize bytes. The zero value is an empty window with fingerprint 0.
+type rabinWindow struct {
+	buf    [rabinWindowSize]byte
+	pos    uint8
+	digest uint64
+}
+
+// roll slides b into the window and returns the new fingerprint.
+func (w *rabinWindow) roll(t *rabinTables, b byte) uint64 {
+	out := w.buf[w.pos]
+	w.buf[w.pos] = b
+	w.pos = (w.pos + 1) % rabinWindowSize
+
+	d := w.digest ^ t.out[out]
+	d = (d<<8 | uint64(b)) ^ t.mod[d>>rabinShift]
+	w.digest = d
+
+	return d
+}
+
+// findBoundaryRabin implements FindBoundary for HashRabin. It follows the same
+// phases as the Gear algorithms, with each chunk hashed from an empty window
+// starting rabinWindowSize bytes before minSize, so that the fingerprint at
+// minSize covers a full window of the chunk's content.
+func (c *ChunkerCore) findBoundaryRabin(data []byte) (boundary int, hash uint64, found bool) {
+	if c.position == 0 {
+		c.rabin = rabinWindow{}
+	}
+
+	t := c.rabinTables
+	start := int(c.position)
+	minSize := int(c.minSize)
+	normSize := int(c.normSize)
+	maxSize := int(c.maxSize)
+	// Skip the bytes before the first window without hashing
+	i := min(max(minSize-rabinWindowSize-start, 0), len(data))
+
+	for ; i < len(data); i++ {
+		pos := start + i // Index of data[i] within the chunk
+
+		fp := c.rabin.roll(t, data[i])
+		if pos < minSize {
+			continue
+		}
+
+		mask, match := c.maskL, c.matchL
+		if c.nRegions != 0 {
+			mask, match = c.regionMask(pos)
+		} else if pos < normSize {
+			mask, match = c.maskS, c.matchS
+		}
+
+		if fp&mask == match || pos+1 >= maxSize {
+			c.fingerprint = fp
+			c.position = 0
+			c.forced = fp&mask != match
+
+			return i + 1, fp, true
+		}
+	}
+
+	c.fingerprint = c.rabin.digest
+	c.position = uint32(start + len(data)) //nolint:gosec // G115
+
+	return len(data), c.fingerprint, false
+}
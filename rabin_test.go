@@ -0,0 +1,163 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"math/bits"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// rabinFingerprint computes the Rabin fingerprint of window from scratch.
+func rabinFingerprint(window []byte) uint64 {
+	var h uint64
+
+	for _, b := range window {
+		h = h<<8 | uint64(b)
+		for bits.Len64(h) >= 54 {
+			h ^= uint64(fastcdc.RabinPolynomial) << (bits.Len64(h) - 54)
+		}
+	}
+
+	return h
+}
+
+// referenceRabinBoundaries chunks data with fingerprints computed from scratch
+// over the last 64 bytes of each chunk, as a reference for the rolling update.
+func referenceRabinBoundaries(data []byte, minSize, normSize, maxSize int, maskS, maskL uint64) []cut {
+	var (
+		cuts  []cut
+		start int
+	)
+
+	for i := range data {
+		size := i - start + 1
+		if size <= minSize {
+			continue
+		}
+
+		fp := rabinFingerprint(data[max(start, i-63) : i+1])
+
+		mask := maskL
+		if size <= normSize {
+			mask = maskS
+		}
+
+		if fp&mask == 0 || size >= maxSize {
+			cuts = append(cuts, cut{i + 1, fp})
+			start = i + 1
+		}
+	}
+
+	return cuts
+}
+
+func TestRabinMatchesReference(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(256*1024, 1289)
+
+	for _, tt := range []struct {
+		minSize, targetSize, maxSize uint32
+	}{
+		{32, 1024, 4 * 1024}, // minSize below the window size
+		{2 * 1024, 8 * 1024, 32 * 1024},
+		{4 * 1024, 16 * 1024, 20 * 1024},
+	} {
+		core, err := fastcdc.NewChunkerCore(
+			fastcdc.WithRollingHash(fastcdc.HashRabin),
+			fastcdc.WithMinSize(tt.minSize),
+			fastcdc.WithTargetSize(tt.targetSize),
+			fastcdc.WithMaxSize(tt.maxSize),
+		)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		maskL := uint64(tt.targetSize) - 1
+		want := referenceRabinBoundaries(data, int(tt.minSize), int(core.NormSize()), int(tt.maxSize), maskL>>1, maskL)
+
+		var got []cut
+
+		core.FindAllBoundaries(data, func(offset, length int, hash uint64) {
+			got = append(got, cut{offset + length, hash})
+		})
+
+		if !slices.Equal(got, want) {
+			t.Errorf("sizes %d/%d/%d: got %d boundaries, want %d", tt.minSize, tt.targetSize, tt.maxSize, len(got), len(want))
+		}
+	}
+}
+
+func TestRabinSplitInput(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1)
+	opts := []fastcdc.Option{fastcdc.WithRollingHash(fastcdc.HashRabin), fastcdc.WithTargetSize(32 * 1024)}
+
+	want, err := fastcdc.GoldenBoundaries(data, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Feed the data in pieces smaller than the window
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var (
+		got   []int
+		start int
+	)
+
+	for offset := 0; offset < len(data); {
+		end := min(offset+37, len(data))
+
+		boundary, _, found := core.FindBoundary(data[offset:end])
+		if found {
+			got = append(got, offset+boundary)
+			offset += boundary
+			start = offset
+
+			continue
+		}
+
+		offset = end
+	}
+
+	if start < len(data) {
+		got = append(got, len(data))
+	}
+
+	if !slices.Equal(got, want) {
+		t.Errorf("split input: got %d boundaries, want %d", len(got), len(want))
+	}
+
+	gear, err := fastcdc.GoldenBoundaries(data, fastcdc.WithTargetSize(32*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if slices.Equal(gear, want) {
+		t.Error("Rabin and Gear produced the same boundaries")
+	}
+
+	if err := fastcdc.VerifyRoundTrip(bytes.NewReader(data), opts...); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestWithRollingHashErrors(t *testing.T) {
+	t.Parallel()
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(fastcdc.HashRabin + 1)); !errors.Is(err, fastcdc.ErrUnsupportedRollingHash) {
+		t.Errorf("expected ErrUnsupportedRollingHash, got %v", err)
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(fastcdc.HashRabin), fastcdc.WithSeed(1)); !errors.Is(err, fastcdc.ErrGearTableWithRabin) {
+		t.Errorf("expected ErrGearTableWithRabin, got %v", err)
+	}
+}
@@ -0,0 +1,41 @@
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrInvalidRange is returned by NewRangeChunker when the range has a negative
+// offset or length.
+var ErrInvalidRange = errors.New("range offset and length must not be negative")
+
+// NewRangeChunker creates a Chunker over the bytes [off, off+length) of r, such
+// as one member of a tar or zip archive at a known position. Only that range is
+// read, with ReadAt, so r is never seeked and may be shared with other readers.
+// The range ends early if r does.
+//
+// Chunk offsets and Offset are relative to off, so the first chunk starts at 0,
+// unless WithAbsoluteOffsets is set, in which case they are positions in r.
+// Boundaries are the same either way. Reset drops the range: the new reader is
+// chunked from offset 0.
+func NewRangeChunker(r io.ReaderAt, off, length int64, opts ...Option) (*Chunker, error) {
+	if r == nil {
+		return nil, ErrNilReader
+	}
+
+	if off < 0 || length < 0 {
+		return nil, fmt.Errorf("%w: offset (%d), length (%d)", ErrInvalidRange, off, length)
+	}
+
+	c, err := NewChunker(io.NewSectionReader(r, off, length), opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.cfg.absoluteOffsets {
+		c.base = uint64(off)
+	}
+
+	return c, nil
+}
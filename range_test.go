@@ -0,0 +1,135 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestNewRangeChunker(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1)
+	off, length := int64(100_000), int64(600_000)
+	member := data[off : off+length]
+
+	var want []fastcdc.Chunk
+
+	err := mustChunker(t, bytes.NewReader(member)).Process(func(chunk fastcdc.Chunk) error {
+		want = append(want, chunk)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, absolute := range []bool{false, true} {
+		var opts []fastcdc.Option
+		if absolute {
+			opts = append(opts, fastcdc.WithAbsoluteOffsets())
+		}
+
+		c, err := fastcdc.NewRangeChunker(bytes.NewReader(data), off, length, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		base := uint64(0)
+		if absolute {
+			base = uint64(off)
+		}
+
+		var got []fastcdc.Chunk
+
+		err = c.Process(func(chunk fastcdc.Chunk) error {
+			if !bytes.Equal(chunk.Data, data[chunk.Offset-base+uint64(off):][:chunk.Length]) {
+				t.Errorf("absolute=%v: chunk at %d does not match its offset", absolute, chunk.Offset)
+			}
+
+			chunk.Offset -= base
+			chunk.Data = nil
+			got = append(got, chunk)
+
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(got) != len(want) {
+			t.Fatalf("absolute=%v: got %d chunks, want %d", absolute, len(got), len(want))
+		}
+
+		for i := range want {
+			if got[i].Offset != want[i].Offset || got[i].Length != want[i].Length || got[i].Hash != want[i].Hash {
+				t.Errorf("absolute=%v: chunk %d = %+v, want %+v", absolute, i, got[i], want[i])
+			}
+		}
+
+		if c.Offset() != base+uint64(length) {
+			t.Errorf("absolute=%v: Offset() = %d, want %d", absolute, c.Offset(), base+uint64(length))
+		}
+	}
+}
+
+func TestNewRangeChunkerPastEnd(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(10_000, 2)
+
+	c, err := fastcdc.NewRangeChunker(bytes.NewReader(data), 4_000, 1<<20, fastcdc.WithAbsoluteOffsets())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunk, err := c.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chunk.Offset != 4_000 || !bytes.Equal(chunk.Data, data[4_000:]) || !chunk.Last {
+		t.Errorf("got chunk at %d of %d bytes, want the 6000 bytes from 4000", chunk.Offset, chunk.Length)
+	}
+
+	if _, err := c.Next(); !errors.Is(err, io.EOF) {
+		t.Errorf("got %v, want io.EOF", err)
+	}
+
+	// Reset drops the range and its base offset
+	c.Reset(bytes.NewReader(data))
+
+	chunk, err = c.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chunk.Offset != 0 {
+		t.Errorf("after Reset, first chunk at %d, want 0", chunk.Offset)
+	}
+}
+
+func TestNewRangeChunkerErrors(t *testing.T) {
+	t.Parallel()
+
+	r := bytes.NewReader(nil)
+
+	if _, err := fastcdc.NewRangeChunker(nil, 0, 1); !errors.Is(err, fastcdc.ErrNilReader) {
+		t.Errorf("nil reader: got %v, want ErrNilReader", err)
+	}
+
+	if _, err := fastcdc.NewRangeChunker(r, -1, 1); !errors.Is(err, fastcdc.ErrInvalidRange) {
+		t.Errorf("negative offset: got %v, want ErrInvalidRange", err)
+	}
+
+	if _, err := fastcdc.NewRangeChunker(r, 0, -1); !errors.Is(err, fastcdc.ErrInvalidRange) {
+		t.Errorf("negative length: got %v, want ErrInvalidRange", err)
+	}
+
+	if _, err := fastcdc.NewRangeChunker(r, 0, 1, fastcdc.WithMinSize(0)); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("invalid option: got %v, want ErrInvalidMinSize", err)
+	}
+}
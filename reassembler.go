@@ -0,0 +1,101 @@
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrManifestNotContiguous is returned by a Reassembler when a manifest chunk does
+// not start where the previous one ended, leaving a gap or an overlap.
+var ErrManifestNotContiguous = errors.New("manifest chunks are not contiguous")
+
+// Reassembler writes chunks fetched from a store back into a stream, in the
+// order given by a manifest from Chunker.Manifest, and checks that they
+// reproduce it. Each call to Write must pass exactly one chunk.
+type Reassembler struct {
+	w        io.Writer
+	manifest []ChunkRef
+	next     int    // Index in manifest of the next chunk to write
+	written  uint64 // Bytes written so far, the offset of the next chunk
+	err      error  // First error, returned by all later calls
+}
+
+// NewReassembler returns a Reassembler writing to w the chunks listed in
+// manifest, which it does not modify.
+func NewReassembler(w io.Writer, manifest []ChunkRef) *Reassembler {
+	return &Reassembler{w: w, manifest: manifest}
+}
+
+// Write checks chunk against the next manifest entry and forwards it to the
+// underlying writer. It returns an error wrapping ErrManifestNotContiguous if
+// the entry does not start where the previous chunk ended, ErrChunkLengthMismatch
+// if chunk does not have its length, and ErrManifestLengthMismatch if all chunks
+// were already written; nothing is written then. An underlying writer that
+// accepts less than chunk without an error fails with io.ErrShortWrite. Errors
+// are sticky: after the first one, all calls fail with it.
+func (r *Reassembler) Write(chunk []byte) (int, error) {
+	if r.err != nil {
+		return 0, r.err
+	}
+
+	if r.next == len(r.manifest) {
+		r.err = fmt.Errorf("%w: chunk of %d bytes past the %d chunks of the manifest",
+			ErrManifestLengthMismatch, len(chunk), len(r.manifest))
+
+		return 0, r.err
+	}
+
+	ref := r.manifest[r.next]
+
+	if ref.Offset != r.written {
+		r.err = fmt.Errorf("%w: chunk %d at offset %d, previous chunks end at %d",
+			ErrManifestNotContiguous, r.next, ref.Offset, r.written)
+
+		return 0, r.err
+	}
+
+	if len(chunk) != int(ref.Length) {
+		r.err = fmt.Errorf("%w: chunk at offset %d: expected %d bytes, got %d",
+			ErrChunkLengthMismatch, ref.Offset, ref.Length, len(chunk))
+
+		return 0, r.err
+	}
+
+	n, err := r.w.Write(chunk)
+	r.written += uint64(n) //nolint:gosec // G115
+
+	if err == nil && n < len(chunk) {
+		err = io.ErrShortWrite
+	}
+
+	if err != nil {
+		r.err = fmt.Errorf("writing chunk at offset %d: %w", ref.Offset, err)
+
+		return n, r.err
+	}
+
+	r.next++
+
+	return n, nil
+}
+
+// Written returns the number of bytes written to the underlying writer.
+func (r *Reassembler) Written() uint64 {
+	return r.written
+}
+
+// Close checks that every chunk of the manifest was written, returning an error
+// wrapping ErrManifestLengthMismatch otherwise, or the error of an earlier
+// call. It does not close the underlying writer.
+func (r *Reassembler) Close() error {
+	if r.err != nil {
+		return r.err
+	}
+
+	if r.next != len(r.manifest) {
+		return fmt.Errorf("%w: wrote %d of %d chunks", ErrManifestLengthMismatch, r.next, len(r.manifest))
+	}
+
+	return nil
+}
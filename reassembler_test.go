@@ -0,0 +1,103 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestReassembler(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1309)
+	opts := []fastcdc.Option{fastcdc.WithMinSize(4 * 1024), fastcdc.WithTargetSize(16 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	refs, err := mustChunker(t, bytes.NewReader(data), opts...).Manifest()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	chunkOf := func(ref fastcdc.ChunkRef) []byte {
+		return data[ref.Offset : ref.Offset+uint64(ref.Length)]
+	}
+
+	var out bytes.Buffer
+
+	r := fastcdc.NewReassembler(&out, refs)
+	for _, ref := range refs {
+		if _, err := r.Write(chunkOf(ref)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(out.Bytes(), data) || r.Written() != uint64(len(data)) {
+		t.Errorf("reassembled %d bytes that differ from the input", out.Len())
+	}
+
+	// reassemble writes the chunks and returns the first error
+	reassemble := func(manifest []fastcdc.ChunkRef, chunks [][]byte) error {
+		r := fastcdc.NewReassembler(&bytes.Buffer{}, manifest)
+
+		for _, chunk := range chunks {
+			if _, err := r.Write(chunk); err != nil {
+				// Errors are sticky
+				if err2 := r.Close(); !errors.Is(err2, err) {
+					t.Errorf("Close after %v returned %v", err, err2)
+				}
+
+				return err
+			}
+		}
+
+		return r.Close()
+	}
+
+	chunks := make([][]byte, len(refs))
+	for i, ref := range refs {
+		chunks[i] = chunkOf(ref)
+	}
+
+	swapped := append([][]byte{chunks[1], chunks[0]}, chunks[2:]...)
+	gap := append([]fastcdc.ChunkRef{refs[0]}, refs[2:]...)
+	overlap := append([]fastcdc.ChunkRef{refs[0], {Offset: refs[0].Offset + 1, Length: refs[1].Length}}, refs[2:]...)
+
+	tests := []struct {
+		name     string
+		manifest []fastcdc.ChunkRef
+		chunks   [][]byte
+		want     error
+	}{
+		{"out of order", refs, swapped, fastcdc.ErrChunkLengthMismatch},
+		{"missing chunk", refs, chunks[:len(chunks)-1], fastcdc.ErrManifestLengthMismatch},
+		{"extra chunk", refs, append(chunks[:len(chunks):len(chunks)], []byte("x")), fastcdc.ErrManifestLengthMismatch},
+		{"gap", gap, append([][]byte{chunks[0]}, chunks[2:]...), fastcdc.ErrManifestNotContiguous},
+		{"overlap", overlap, chunks, fastcdc.ErrManifestNotContiguous},
+	}
+
+	for _, tt := range tests {
+		if err := reassemble(tt.manifest, tt.chunks); !errors.Is(err, tt.want) {
+			t.Errorf("%s: got %v, want %v", tt.name, err, tt.want)
+		}
+	}
+
+	// A writer accepting part of a chunk without an error
+	short := fastcdc.NewReassembler(&limitWriter{n: len(chunks[0]) + 10}, refs)
+	if _, err := short.Write(chunks[0]); err != nil {
+		t.Fatal(err)
+	}
+
+	if n, err := short.Write(chunks[1]); n != 10 || !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Short write: got (%d, %v), want (10, %v)", n, err, io.ErrShortWrite)
+	}
+
+	if err := short.Close(); !errors.Is(err, io.ErrShortWrite) {
+		t.Errorf("Close after a short write returned %v", err)
+	}
+}
@@ -0,0 +1,97 @@
+package fastcdc
+
+// maxNormRegions is the largest number of regions accepted by WithNormRegions.
+const maxNormRegions = 8
+
+// normRegion is one region of WithNormRegions, with the mask tested in it.
+type normRegion struct {
+	end   uint32 // Position within the chunk where the region ends (exclusive)
+	mask  uint64
+	match uint64 // Value fp & mask must equal at a boundary
+}
+
+// computeRegions returns the regions of WithNormRegions, or 0 regions for the
+// two-mask scheme. Region i starts at minSize + i*(normSize-minSize) and its
+// mask has normStrength*(n-1-i) fewer bits than maskL, so the first region is
+// maskS and the last one is maskL. The last region ends at maxSize, which also
+// bounds the others.
+func (c *config) computeRegions() (regions [maxNormRegions]normRegion, n uint8) {
+	if c.normRegions <= 2 {
+		return regions, 0
+	}
+
+	_, _, normSize, bits := c.computeMasks()
+	step := uint64(normSize - c.minSize)
+
+	for i := range c.normRegions {
+		end := uint64(c.maxSize)
+		if i < c.normRegions-1 {
+			end = min(uint64(c.minSize)+uint64(i+1)*step, end) //nolint:gosec // G115
+		}
+
+		regionBits := bits - c.normStrength*uint8(c.normRegions-1-i) //nolint:gosec // G115: validated
+		mask := uint64(1)<<regionBits - 1
+
+		regions[i] = normRegion{
+			end:   uint32(end), //nolint:gosec // G115: at most maxSize
+			mask:  mask,
+			match: c.boundary & mask,
+		}
+	}
+
+	return regions, uint8(c.normRegions) //nolint:gosec // G115: at most maxNormRegions
+}
+
+// findBoundaryRegions implements FindBoundary for the Gear hash with more than
+// two regions (WithNormRegions). Within a region it matches AlgorithmV1, which
+// handles the default two regions with a faster loop.
+func (c *ChunkerCore) findBoundaryRegions(data []byte) (boundary int, hash uint64, found bool) {
+	fp := c.fingerprint
+	start := int(c.position)
+
+	// Skip to minSize without hashing
+	i := min(max(int(c.minSize)-start, 0), len(data))
+	if c.fromStart {
+		fp = c.warmUp(fp, data[:i], start)
+	}
+
+	for r := range c.nRegions {
+		region := &c.regions[r]
+		end := min(int(region.end)-start, len(data))
+
+		for ; i < end; i++ {
+			fp = (fp << 1) + c.table[data[i]]
+			if fp&region.mask == region.match {
+				c.fingerprint = fp
+				c.position = 0
+
+				return i + 1, fp, true
+			}
+		}
+	}
+
+	// Hard limit at maxSize
+	if start+i >= int(c.maxSize) {
+		c.fingerprint = fp
+		c.position = 0
+		c.forced = true
+
+		return i, fp, true
+	}
+
+	c.fingerprint = fp
+	c.position = uint32(start + i) //nolint:gosec // G115
+
+	return i, fp, false
+}
+
+// regionMask returns the mask and match value of the region holding the byte
+// at position pos within the chunk, for more than two regions.
+func (c *ChunkerCore) regionMask(pos int) (mask, match uint64) {
+	r := 0
+	for r < int(c.nRegions)-1 && pos >= int(c.regions[r].end) {
+		r++
+	}
+
+	return c.regions[r].mask, c.regions[r].match
+}
@@ -0,0 +1,29 @@
+package fastcdc
+
+import (
+	"errors"
+	"io"
+)
+
+// retryRead retries a failed read into c.buf[n+m:] for WithRetry, after m bytes
+// were read into c.buf[n:] before the error, rewinding the reader to just after
+// them first. It returns the total bytes read after n and the final error.
+func (c *Chunker) retryRead(n, m int, err error) (int, error) {
+	for range c.cfg.retries {
+		if err == nil || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			break
+		}
+
+		// The reader may have advanced past the bytes it returned
+		next := c.seekStart + int64(c.offset) + int64(n+m) //nolint:gosec // G115
+		if _, serr := c.seeker.Seek(next, io.SeekStart); serr != nil {
+			break
+		}
+
+		var k int
+		k, err = io.ReadFull(c.reader, c.buf[n+m:])
+		m += k
+	}
+
+	return m, err
+}
@@ -0,0 +1,89 @@
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrSelfTest is returned by SelfTest when a ChunkerCore fails one of its checks.
+var ErrSelfTest = errors.New("chunker self-test failed")
+
+// selfTestSize is the size of the SelfTest vector, enough for a few chunks of
+// the default maxSize.
+const selfTestSize = 1 << 20
+
+// selfTestVector returns the input chunked by SelfTest, generated once.
+//
+//nolint:gochecknoglobals
+var selfTestVector = sync.OnceValue(func() []byte { return TestData(0x5e1f7e57, selfTestSize) })
+
+// SelfTest checks that c is correctly configured and chunks correctly, as a
+// cheap health check at startup before chunking real data. It returns an error
+// wrapping ErrSelfTest describing the first failed check:
+//
+//   - A Gear table that is not the one of the seed, as installed by WithTable,
+//     must have 256 distinct entries. A zeroed or otherwise damaged table maps
+//     several bytes to the same value.
+//   - Chunking a fixed 1 MiB vector must give the same boundaries and
+//     fingerprints with the unrolled and plain Gear loops (see WithUnroll), and
+//     every chunk but the last must be between minSize and maxSize bytes.
+//   - If the vector holds at least 4 chunks of maxSize, at least one boundary
+//     must be content-defined rather than cut at maxSize, unless WithFixedSize
+//     is set.
+//
+// SelfTest does not change the state of c. The vector is generated on the first
+// call, which allocates 1 MiB kept for later calls.
+func (c *ChunkerCore) SelfTest() error {
+	if c.rolling == HashGear && !c.fixed && c.table != GenerateTable(c.seed) {
+		seen := make(map[uint64]int, len(c.table))
+		for i, v := range c.table {
+			if j, ok := seen[v]; ok {
+				return fmt.Errorf("%w: table entries %d and %d are both %#x", ErrSelfTest, j, i, v)
+			}
+
+			seen[v] = i
+		}
+	}
+
+	data := selfTestVector()
+
+	unrolled, plain := *c, *c
+	unrolled.scalar = false
+	plain.scalar = true
+
+	var contentDefined int
+
+	for start := 0; start < len(data); {
+		unrolled.Reset()
+		plain.Reset()
+
+		n, hash, found := unrolled.FindBoundary(data[start:])
+		m, plainHash, plainFound := plain.FindBoundary(data[start:])
+
+		if n != m || hash != plainHash || found != plainFound {
+			return fmt.Errorf("%w: boundary at %d (%#x) differs from the plain loop at %d (%#x)",
+				ErrSelfTest, start+n, hash, start+m, plainHash)
+		}
+
+		if !found {
+			break
+		}
+
+		if n < int(c.minSize) || n > int(c.maxSize) {
+			return fmt.Errorf("%w: chunk at %d has %d bytes, outside [%d, %d]", ErrSelfTest, start, n, c.minSize, c.maxSize)
+		}
+
+		if !unrolled.forced {
+			contentDefined++
+		}
+
+		start += n
+	}
+
+	if !c.fixed && contentDefined == 0 && uint64(len(data)) >= 4*uint64(c.maxSize) {
+		return fmt.Errorf("%w: every chunk of %d bytes was cut at maxSize (%d)", ErrSelfTest, len(data), c.maxSize)
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package fastcdc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestSelfTest(t *testing.T) {
+	t.Parallel()
+
+	small := []fastcdc.Option{fastcdc.WithMinSize(2 * 1024), fastcdc.WithTargetSize(8 * 1024), fastcdc.WithMaxSize(64 * 1024)}
+
+	for name, opts := range map[string][]fastcdc.Option{
+		"default":     nil,
+		"small":       small,
+		"seed":        {fastcdc.WithSeed(1333)},
+		"table":       {fastcdc.WithTable(fastcdc.GenerateTable(1333))},
+		"plain":       {fastcdc.WithUnroll(false)},
+		"from start":  append(small, fastcdc.WithHashFromStart()),
+		"regions":     {fastcdc.WithNormRegions(4)},
+		"rabin":       {fastcdc.WithRollingHash(fastcdc.HashRabin)},
+		"fixed":       {fastcdc.WithFixedSize(4096)},
+		"target bits": append(small, fastcdc.WithTargetBits(12)),
+		"large":       {fastcdc.WithMinSize(1 << 20), fastcdc.WithTargetSize(4 << 20), fastcdc.WithMaxSize(16 << 20)},
+	} {
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The state of core is left alone
+		core.FindBoundary(randBytes(1000, 1333))
+
+		if err := core.SelfTest(); err != nil {
+			t.Errorf("%s: %v", name, err)
+		}
+
+		if core.Position() != 1000 {
+			t.Errorf("%s: SelfTest moved the position to %d", name, core.Position())
+		}
+	}
+
+	// A damaged table, with two bytes hashed alike
+	table := fastcdc.GenerateTable(1333)
+	table[200] = table[100]
+
+	core, err := fastcdc.NewChunkerCore(fastcdc.WithTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := core.SelfTest(); !errors.Is(err, fastcdc.ErrSelfTest) {
+		t.Errorf("duplicated table entry: got %v, want ErrSelfTest", err)
+	}
+
+	// Distinct entries whose low 16 bits keep the fingerprint away from 0
+	for i := range table {
+		table[i] = uint64(i)<<32 | 0xffff
+	}
+
+	core, err = fastcdc.NewChunkerCore(fastcdc.WithTable(table))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := core.SelfTest(); !errors.Is(err, fastcdc.ErrSelfTest) {
+		t.Errorf("no content-defined boundaries: got %v, want ErrSelfTest", err)
+	}
+
+	// A ChunkerCore not built by NewChunkerCore has a zero table
+	if err := new(fastcdc.ChunkerCore).SelfTest(); !errors.Is(err, fastcdc.ErrSelfTest) {
+		t.Errorf("zero ChunkerCore: got %v, want ErrSelfTest", err)
+	}
+}
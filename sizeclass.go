@@ -0,0 +1,93 @@
+package fastcdc
+
+import (
+	"bytes"
+	"errors"
+	"io"
+)
+
+// SizeClass classifies a chunk by where its length falls relative to the
+// configured minimum, normalization and maximum sizes.
+type SizeClass uint8
+
+const (
+	// SizeClassTail is a chunk shorter than minSize, which only happens for the final chunk.
+	SizeClassTail SizeClass = iota
+
+	// SizeClassNormalized is a chunk cut in the normalized region [minSize, normSize].
+	SizeClassNormalized
+
+	// SizeClassStandard is a chunk cut in the standard region (normSize, maxSize).
+	SizeClassStandard
+
+	// SizeClassMax is a chunk of exactly maxSize, usually a forced cut.
+	SizeClassMax
+)
+
+// String returns the name of the size class.
+func (s SizeClass) String() string {
+	switch s {
+	case SizeClassTail:
+		return "tail"
+	case SizeClassNormalized:
+		return "normalized"
+	case SizeClassStandard:
+		return "standard"
+	case SizeClassMax:
+		return "max"
+	default:
+		return "unknown"
+	}
+}
+
+// SizeClassOf returns the size class of a chunk of the given length.
+func (c *ChunkerCore) SizeClassOf(length uint32) SizeClass {
+	switch {
+	case length < c.minSize:
+		return SizeClassTail
+	case length <= c.normSize:
+		return SizeClassNormalized
+	case length < c.maxSize:
+		return SizeClassStandard
+	default:
+		return SizeClassMax
+	}
+}
+
+// ChunkGrouped chunks r and groups the chunks by size class.
+// Within each group chunks are in stream order.
+//
+// Each Chunk.Data is a copy owned by the caller, so the result retains the
+// entire input in memory. With WithGuardedData the copy is in Data and Guarded
+// is left empty. Use it for analysis of bounded inputs; for large
+// streams, classify chunks from Next() with SizeClassOf instead.
+func ChunkGrouped(r io.Reader, opts ...Option) (map[SizeClass][]Chunk, error) {
+	chunker, err := NewChunker(r, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[SizeClass][]Chunk)
+
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if chunker.cfg.guardedData {
+			chunk.Data, chunk.Guarded = bytes.Clone(chunk.Guarded.Bytes()), ChunkData{}
+		} else {
+			chunk.Data = bytes.Clone(chunk.Data)
+		}
+
+		class := chunker.core.SizeClassOf(chunk.Length)
+		groups[class] = append(groups[class], chunk)
+	}
+
+	return groups, nil
+}
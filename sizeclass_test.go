@@ -0,0 +1,80 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestChunkGrouped verifies that groups partition the chunks and reconstruct
+// the input, including with guarded data.
+func TestChunkGrouped(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 2*1024*1024+123)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, guarded := range []bool{false, true} {
+		t.Run(fmt.Sprintf("guarded=%t", guarded), func(t *testing.T) {
+			t.Parallel()
+
+			testChunkGrouped(t, data, guarded)
+		})
+	}
+}
+
+func testChunkGrouped(t *testing.T, data []byte, guarded bool) {
+	t.Helper()
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(4 * 1024),
+		fastcdc.WithTargetSize(16 * 1024),
+		fastcdc.WithMaxSize(32 * 1024),
+		fastcdc.WithGuardedData(guarded),
+	}
+
+	groups, err := fastcdc.ChunkGrouped(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	core, err := fastcdc.NewChunkerCore(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var all []fastcdc.Chunk
+
+	for class, chunks := range groups {
+		for _, chunk := range chunks {
+			if got := core.SizeClassOf(chunk.Length); got != class {
+				t.Errorf("Chunk of %d bytes in group %s, want %s", chunk.Length, class, got)
+			}
+		}
+
+		all = append(all, chunks...)
+	}
+
+	slices.SortFunc(all, func(a, b fastcdc.Chunk) int {
+		return int(a.Offset) - int(b.Offset) //nolint:gosec // G115
+	})
+
+	var rebuilt []byte
+	for _, chunk := range all {
+		rebuilt = append(rebuilt, chunk.Data...)
+	}
+
+	if !bytes.Equal(rebuilt, data) {
+		t.Error("Concatenated groups do not reconstruct the input")
+	}
+
+	t.Logf("normalized=%d standard=%d max=%d tail=%d",
+		len(groups[fastcdc.SizeClassNormalized]), len(groups[fastcdc.SizeClassStandard]),
+		len(groups[fastcdc.SizeClassMax]), len(groups[fastcdc.SizeClassTail]))
+}
@@ -0,0 +1,86 @@
+package fastcdc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrInvalidSizeString is returned when a size string cannot be parsed.
+var ErrInvalidSizeString = errors.New("invalid size string")
+
+// sizeUnits maps the accepted unit suffixes to their multipliers. Single-letter
+// and lowercase-b forms such as "K", "k" or "kb" are rejected as ambiguous
+// between decimal and binary units, or bits and bytes.
+//
+//nolint:gochecknoglobals
+var sizeUnits = map[string]uint64{
+	"":    1,
+	"B":   1,
+	"kB":  1000,
+	"KB":  1000,
+	"MB":  1000 * 1000,
+	"GB":  1000 * 1000 * 1000,
+	"KiB": 1024,
+	"MiB": 1024 * 1024,
+	"GiB": 1024 * 1024 * 1024,
+}
+
+// ParseSize parses a size in bytes written with an optional unit, such as "65536",
+// "64KiB", "1MB" or "256 KiB". Binary units (KiB, MiB, GiB) are powers of 1024
+// and decimal units (kB or KB, MB, GB) powers of 1000. The number must be a
+// non-negative integer and the size must fit in a uint32.
+func ParseSize(s string) (uint32, error) {
+	trimmed := strings.TrimSpace(s)
+
+	digits := strings.IndexFunc(trimmed, func(r rune) bool { return r < '0' || r > '9' })
+	if digits < 0 {
+		digits = len(trimmed)
+	}
+
+	number, unit := trimmed[:digits], strings.TrimSpace(trimmed[digits:])
+
+	multiplier, ok := sizeUnits[unit]
+	if number == "" || !ok {
+		return 0, fmt.Errorf("%w: %q", ErrInvalidSizeString, s)
+	}
+
+	n, err := strconv.ParseUint(number, 10, 32)
+	if err != nil || n*multiplier > math.MaxUint32 {
+		return 0, fmt.Errorf("%w: %q does not fit in a uint32", ErrInvalidSizeString, s)
+	}
+
+	return uint32(n * multiplier), nil //nolint:gosec // G115: checked above
+}
+
+// WithMinSizeString is WithMinSize with a size parsed by ParseSize, for sizes
+// given on command lines or in configuration files.
+func WithMinSizeString(s string) Option {
+	return withSizeString(s, WithMinSize)
+}
+
+// WithTargetSizeString is WithTargetSize with a size parsed by ParseSize, for
+// sizes given on command lines or in configuration files, such as "64KiB".
+func WithTargetSizeString(s string) Option {
+	return withSizeString(s, WithTargetSize)
+}
+
+// WithMaxSizeString is WithMaxSize with a size parsed by ParseSize, for sizes
+// given on command lines or in configuration files.
+func WithMaxSizeString(s string) Option {
+	return withSizeString(s, WithMaxSize)
+}
+
+// withSizeString parses s and applies the numeric option built by opt.
+func withSizeString(s string, opt func(uint32) Option) Option {
+	return func(c *config) error {
+		size, err := ParseSize(s)
+		if err != nil {
+			return err
+		}
+
+		return opt(size)(c)
+	}
+}
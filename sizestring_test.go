@@ -0,0 +1,62 @@
+package fastcdc_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestParseSize(t *testing.T) {
+	t.Parallel()
+
+	for _, tt := range []struct {
+		in   string
+		want uint32
+	}{
+		{"65536", 65536},
+		{"64KiB", 64 * 1024},
+		{"256 KiB", 256 * 1024},
+		{"1MB", 1000 * 1000},
+		{"16kB", 16000},
+		{"4 MiB", 4 * 1024 * 1024},
+		{" 1GiB ", 1024 * 1024 * 1024},
+		{"512B", 512},
+	} {
+		got, err := fastcdc.ParseSize(tt.in)
+		if err != nil || got != tt.want {
+			t.Errorf("ParseSize(%q) = %d, %v; want %d", tt.in, got, err, tt.want)
+		}
+	}
+
+	for _, in := range []string{"", "KiB", "64K", "64k", "64kb", "64 kib", "1.5MiB", "-1", "4GiB", "64KiB!", "0x10"} {
+		if _, err := fastcdc.ParseSize(in); !errors.Is(err, fastcdc.ErrInvalidSizeString) {
+			t.Errorf("ParseSize(%q): expected ErrInvalidSizeString, got %v", in, err)
+		}
+	}
+}
+
+func TestWithSizeStrings(t *testing.T) {
+	t.Parallel()
+
+	core, err := fastcdc.NewChunkerCore(
+		fastcdc.WithMinSizeString("8KiB"),
+		fastcdc.WithTargetSizeString("32KiB"),
+		fastcdc.WithMaxSizeString("128 KiB"),
+	)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if core.MinSize() != 8*1024 || core.TargetSize() != 32*1024 || core.MaxSize() != 128*1024 {
+		t.Errorf("Sizes = %d/%d/%d, want 8/32/128 KiB", core.MinSize(), core.TargetSize(), core.MaxSize())
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithTargetSizeString("64K")); !errors.Is(err, fastcdc.ErrInvalidSizeString) {
+		t.Errorf("Expected ErrInvalidSizeString, got %v", err)
+	}
+
+	if _, err := fastcdc.NewChunkerCore(fastcdc.WithMinSizeString("0")); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("Expected ErrInvalidMinSize, got %v", err)
+	}
+}
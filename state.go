@@ -0,0 +1,144 @@
+package fastcdc
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+)
+
+var (
+	// ErrInvalidState is returned when a serialized state is malformed.
+	ErrInvalidState = errors.New("invalid serialized state")
+
+	// ErrStateMismatch is returned when a serialized state was produced by a
+	// ChunkerCore with a different configuration.
+	ErrStateMismatch = errors.New("serialized state does not match chunker configuration")
+)
+
+const (
+	// stateVersion is the version of the serialized state format.
+	stateVersion = 3
+
+	// stateSize is the size of a serialized state: version (1) + rolling hash (1)
+	// + seed (8) + minSize, normSize, maxSize (3*4) + fingerprint (8) + position
+	// (4) + Rabin window (rabinWindowSize) + Rabin window position (1) + boundary
+	// rules (8).
+	stateSize = 1 + 1 + 8 + 3*4 + 8 + 4 + rabinWindowSize + 1 + 8
+)
+
+// MarshalState serializes the rolling state (fingerprint and position) so that
+// chunking can be resumed mid-chunk, for example by another worker. With
+// HashRabin the state also holds the bytes of the current window, which the
+// fingerprint alone does not determine.
+//
+// The Gear table is not included, since it is reconstructed from the seed. The
+// rolling hash, seed, chunk sizes and a digest of the other boundary rules
+// (masks, normalization regions, WithHashFromStart and WithFixedSize) are
+// included so that UnmarshalState can verify that it is restoring into a
+// compatibly-configured ChunkerCore.
+func (c *ChunkerCore) MarshalState() []byte {
+	buf := make([]byte, 0, stateSize)
+	buf = append(buf, stateVersion, byte(c.rolling))
+	buf = binary.LittleEndian.AppendUint64(buf, c.seed)
+	buf = binary.LittleEndian.AppendUint32(buf, c.minSize)
+	buf = binary.LittleEndian.AppendUint32(buf, c.normSize)
+	buf = binary.LittleEndian.AppendUint32(buf, c.maxSize)
+	buf = binary.LittleEndian.AppendUint64(buf, c.fingerprint)
+	buf = binary.LittleEndian.AppendUint32(buf, c.position)
+	buf = append(buf, c.rabin.buf[:]...)
+	buf = append(buf, c.rabin.pos)
+	buf = binary.LittleEndian.AppendUint64(buf, c.boundaryRules())
+
+	return buf
+}
+
+// UnmarshalState restores a rolling state produced by MarshalState.
+// Subsequent calls to FindBoundary continue the chunk that was in progress.
+//
+// It returns ErrInvalidState if data is malformed and ErrStateMismatch if the
+// state was produced with a different rolling hash, seed, chunk sizes or
+// boundary rules, leaving the current state unchanged in both cases. Tables
+// installed with WithTable are not part of the state and cannot be verified.
+func (c *ChunkerCore) UnmarshalState(data []byte) error {
+	if len(data) != stateSize || data[0] != stateVersion {
+		return fmt.Errorf("%w: %d bytes", ErrInvalidState, len(data))
+	}
+
+	le := binary.LittleEndian
+	rolling := RollingHash(data[1])
+	seed := le.Uint64(data[2:])
+	minSize := le.Uint32(data[10:])
+	normSize := le.Uint32(data[14:])
+	maxSize := le.Uint32(data[18:])
+	fingerprint := le.Uint64(data[22:])
+	position := le.Uint32(data[30:])
+	window := data[34 : 34+rabinWindowSize]
+	windowPos := data[34+rabinWindowSize]
+	rules := le.Uint64(data[35+rabinWindowSize:])
+
+	if rolling != c.rolling {
+		return fmt.Errorf("%w: rolling hash %d, want %d", ErrStateMismatch, rolling, c.rolling)
+	}
+
+	if seed != c.seed {
+		return fmt.Errorf("%w: seed %d, want %d", ErrStateMismatch, seed, c.seed)
+	}
+
+	if minSize != c.minSize || normSize != c.normSize || maxSize != c.maxSize {
+		return fmt.Errorf("%w: sizes (%d, %d, %d), want (%d, %d, %d)",
+			ErrStateMismatch, minSize, normSize, maxSize, c.minSize, c.normSize, c.maxSize)
+	}
+
+	if want := c.boundaryRules(); rules != want {
+		return fmt.Errorf("%w: boundary rules %#016x, want %#016x", ErrStateMismatch, rules, want)
+	}
+
+	if position >= maxSize {
+		return fmt.Errorf("%w: position %d beyond maxSize %d", ErrInvalidState, position, maxSize)
+	}
+
+	if windowPos >= rabinWindowSize {
+		return fmt.Errorf("%w: window position %d", ErrInvalidState, windowPos)
+	}
+
+	c.fingerprint = fingerprint
+	c.position = position
+	copy(c.rabin.buf[:], window)
+	c.rabin.pos = windowPos
+	c.rabin.digest = fingerprint
+
+	return nil
+}
+
+// boundaryRules returns a digest of the settings other than the rolling hash,
+// seed and sizes that decide where chunks end, for UnmarshalState to compare.
+func (c *ChunkerCore) boundaryRules() uint64 {
+	buf := make([]byte, 0, 4*8+2+maxNormRegions*(4+2*8))
+	buf = binary.LittleEndian.AppendUint64(buf, c.maskS)
+	buf = binary.LittleEndian.AppendUint64(buf, c.maskL)
+	buf = binary.LittleEndian.AppendUint64(buf, c.matchS)
+	buf = binary.LittleEndian.AppendUint64(buf, c.matchL)
+
+	var flags byte
+	if c.fromStart {
+		flags |= 1
+	}
+
+	if c.fixed {
+		flags |= 2
+	}
+
+	buf = append(buf, flags, c.nRegions)
+
+	for _, region := range c.regions[:c.nRegions] {
+		buf = binary.LittleEndian.AppendUint32(buf, region.end)
+		buf = binary.LittleEndian.AppendUint64(buf, region.mask)
+		buf = binary.LittleEndian.AppendUint64(buf, region.match)
+	}
+
+	h := fnv.New64a()
+	h.Write(buf)
+
+	return h.Sum64()
+}
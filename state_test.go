@@ -0,0 +1,143 @@
+package fastcdc_test
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// TestChunkerCoreStateRoundTrip verifies that chunking resumes identically
+// from a serialized state, including a split within the last window of bytes
+// before a boundary.
+func TestChunkerCoreStateRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 512*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		opts  []fastcdc.Option
+		split int // Bytes before the first boundary
+	}{
+		{"Gear", []fastcdc.Option{fastcdc.WithSeed(42), fastcdc.WithTargetSize(64 * 1024)}, 100},
+		{"GearNearBoundary", []fastcdc.Option{fastcdc.WithSeed(42), fastcdc.WithTargetSize(64 * 1024)}, 10},
+		{"Rabin", []fastcdc.Option{fastcdc.WithRollingHash(fastcdc.HashRabin)}, 100},
+		{"RabinNearBoundary", []fastcdc.Option{fastcdc.WithRollingHash(fastcdc.HashRabin)}, 10},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reference, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			wantBoundary, wantHash, wantFound := reference.FindBoundary(data)
+
+			// Feed most of the first chunk, checkpoint, and resume in another core
+			split := wantBoundary - tt.split
+
+			first, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if _, _, found := first.FindBoundary(data[:split]); found {
+				t.Fatal("Boundary found before the split point")
+			}
+
+			state := first.MarshalState()
+
+			second, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if err := second.UnmarshalState(state); err != nil {
+				t.Fatal(err)
+			}
+
+			boundary, hash, found := second.FindBoundary(data[split:])
+			if split+boundary != wantBoundary || hash != wantHash || found != wantFound {
+				t.Errorf("Resumed chunking: (%d, %x, %v), want (%d, %x, %v)",
+					split+boundary, hash, found, wantBoundary, wantHash, wantFound)
+			}
+		})
+	}
+}
+
+// TestChunkerCoreStateMismatch verifies validation of serialized states.
+func TestChunkerCoreStateMismatch(t *testing.T) {
+	t.Parallel()
+
+	source, err := fastcdc.NewChunkerCore(fastcdc.WithSeed(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	state := source.MarshalState()
+
+	otherSeed, err := fastcdc.NewChunkerCore(fastcdc.WithSeed(2))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := otherSeed.UnmarshalState(state); !errors.Is(err, fastcdc.ErrStateMismatch) {
+		t.Errorf("Expected ErrStateMismatch for different seed, got %v", err)
+	}
+
+	otherSize, err := fastcdc.NewChunkerCore(fastcdc.WithSeed(1), fastcdc.WithMaxSize(512*1024))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := otherSize.UnmarshalState(state); !errors.Is(err, fastcdc.ErrStateMismatch) {
+		t.Errorf("Expected ErrStateMismatch for different sizes, got %v", err)
+	}
+
+	rabin, err := fastcdc.NewChunkerCore(fastcdc.WithRollingHash(fastcdc.HashRabin))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	gear, err := fastcdc.NewChunkerCore()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := rabin.UnmarshalState(gear.MarshalState()); !errors.Is(err, fastcdc.ErrStateMismatch) {
+		t.Errorf("Expected ErrStateMismatch for different rolling hash, got %v", err)
+	}
+
+	// Cores with the same sizes but different boundary rules
+	for name, opt := range map[string]fastcdc.Option{
+		"masks":           fastcdc.WithMasks(0x3fff, 0x1ffff),
+		"boundary value":  fastcdc.WithBoundaryValue(1),
+		"norm regions":    fastcdc.WithNormRegions(4),
+		"hash from start": fastcdc.WithHashFromStart(),
+	} {
+		other, err := fastcdc.NewChunkerCore(fastcdc.WithSeed(1), opt)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if other.NormSize() != source.NormSize() {
+			t.Fatalf("%s: normSize %d differs from %d", name, other.NormSize(), source.NormSize())
+		}
+
+		if err := other.UnmarshalState(state); !errors.Is(err, fastcdc.ErrStateMismatch) {
+			t.Errorf("Expected ErrStateMismatch for different %s, got %v", name, err)
+		}
+	}
+
+	if err := source.UnmarshalState(state[:10]); !errors.Is(err, fastcdc.ErrInvalidState) {
+		t.Errorf("Expected ErrInvalidState for truncated state, got %v", err)
+	}
+}
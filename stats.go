@@ -0,0 +1,102 @@
+package fastcdc
+
+import "math"
+
+// ChunkStats summarizes the distribution of chunk sizes.
+type ChunkStats struct {
+	Count  uint64  // Number of chunks
+	Min    uint32  // Smallest chunk size in bytes
+	Max    uint32  // Largest chunk size in bytes
+	Mean   float64 // Mean chunk size in bytes
+	StdDev float64 // Population standard deviation of chunk sizes in bytes
+}
+
+// sizeStats accumulates ChunkStats incrementally using Welford's online
+// algorithm, which is O(1) per chunk and numerically stable.
+type sizeStats struct {
+	count    uint64
+	min, max uint32
+	mean     float64
+	m2       float64 // Sum of squared differences from the mean
+}
+
+// add records a chunk size.
+func (s *sizeStats) add(length uint32) {
+	s.count++
+
+	if s.count == 1 || length < s.min {
+		s.min = length
+	}
+
+	if length > s.max {
+		s.max = length
+	}
+
+	x := float64(length)
+	delta := x - s.mean
+	s.mean += delta / float64(s.count)
+	s.m2 += delta * (x - s.mean)
+}
+
+// summary returns the statistics accumulated so far.
+func (s *sizeStats) summary() ChunkStats {
+	if s.count == 0 {
+		return ChunkStats{}
+	}
+
+	return ChunkStats{
+		Count:  s.count,
+		Min:    s.min,
+		Max:    s.max,
+		Mean:   s.mean,
+		StdDev: math.Sqrt(s.m2 / float64(s.count)),
+	}
+}
+
+// ChunkerStats collects chunk size statistics for diagnosing how well the
+// chunking parameters suit the data. Feed it every chunk with Add.
+//
+// The zero value is ready to use. ChunkerStats is not safe for concurrent use.
+type ChunkerStats struct {
+	count   uint64
+	lengths map[uint32]uint64 // Number of chunks seen per length
+}
+
+// Add records a chunk.
+func (s *ChunkerStats) Add(chunk Chunk) {
+	if s.lengths == nil {
+		s.lengths = make(map[uint32]uint64)
+	}
+
+	s.count++
+	s.lengths[chunk.Length]++
+}
+
+// Count returns the number of chunks recorded.
+func (s *ChunkerStats) Count() uint64 {
+	return s.count
+}
+
+// DominantSize returns the most frequent chunk length and the fraction of all
+// chunks that have it. Ties are broken towards the smaller length.
+//
+// On data without repeating structure, chunk lengths are spread out and the
+// fraction stays small. A large fraction means boundaries cluster at a fixed
+// period, typically because the input repeats with a period that aligns with
+// the mask, and content-defined chunking is degenerating into fixed-size
+// chunking for this data.
+func (s *ChunkerStats) DominantSize() (size uint32, fraction float64) {
+	if s.count == 0 {
+		return 0, 0
+	}
+
+	var best uint64
+
+	for length, n := range s.lengths {
+		if n > best || (n == best && length < size) {
+			size, best = length, n
+		}
+	}
+
+	return size, float64(best) / float64(s.count)
+}
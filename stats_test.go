@@ -0,0 +1,144 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/rand"
+	"errors"
+	"io"
+	"math"
+	"slices"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// collectStats chunks data and records every chunk in a ChunkerStats.
+func collectStats(t *testing.T, data []byte, opts ...fastcdc.Option) *fastcdc.ChunkerStats {
+	t.Helper()
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var stats fastcdc.ChunkerStats
+
+	for {
+		chunk, err := chunker.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		stats.Add(chunk)
+	}
+
+	return &stats
+}
+
+// TestChunkerStatsDominantSize verifies hot spot detection on repeating data.
+func TestChunkerStatsDominantSize(t *testing.T) {
+	t.Parallel()
+
+	const size = 4 * 1024 * 1024
+
+	random := make([]byte, size)
+	if _, err := rand.Read(random); err != nil {
+		t.Fatal(err)
+	}
+
+	// Same pattern as the Compressible benchmark data
+	compressible := make([]byte, size)
+	for i := range compressible {
+		compressible[i] = byte(i % 256)
+	}
+
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(2 * 1024),
+		fastcdc.WithTargetSize(8 * 1024),
+		fastcdc.WithMaxSize(32 * 1024),
+	}
+
+	randomSize, randomFraction := collectStats(t, random, opts...).DominantSize()
+	patternSize, patternFraction := collectStats(t, compressible, opts...).DominantSize()
+
+	t.Logf("Random: dominant %d bytes (%.2f), Compressible: dominant %d bytes (%.2f)",
+		randomSize, randomFraction, patternSize, patternFraction)
+
+	if randomFraction > 0.1 {
+		t.Errorf("Random data shows clustering: %.2f of chunks are %d bytes", randomFraction, randomSize)
+	}
+
+	if patternFraction < 0.9 {
+		t.Errorf("Repeating data not flagged: only %.2f of chunks are %d bytes", patternFraction, patternSize)
+	}
+
+	var empty fastcdc.ChunkerStats
+	if size, fraction := empty.DominantSize(); size != 0 || fraction != 0 {
+		t.Errorf("Empty stats: got (%d, %.2f), want (0, 0)", size, fraction)
+	}
+}
+
+// TestChunkerStats verifies the running size statistics against a direct computation.
+func TestChunkerStats(t *testing.T) {
+	t.Parallel()
+
+	data := make([]byte, 4*1024*1024)
+	if _, err := rand.Read(data); err != nil {
+		t.Fatal(err)
+	}
+
+	chunker, err := fastcdc.NewChunker(bytes.NewReader(data), fastcdc.WithTargetSize(16*1024), fastcdc.WithMinSize(4*1024),
+		fastcdc.WithStats(true))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var sizes []float64
+
+	for chunk, err := range chunker.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		sizes = append(sizes, float64(chunk.Length))
+	}
+
+	var sum float64
+	for _, size := range sizes {
+		sum += size
+	}
+
+	mean := sum / float64(len(sizes))
+
+	var variance float64
+
+	for _, size := range sizes {
+		variance += (size - mean) * (size - mean)
+	}
+
+	stddev := math.Sqrt(variance / float64(len(sizes)))
+
+	stats := chunker.Stats()
+
+	if stats.Count != uint64(len(sizes)) {
+		t.Errorf("Count = %d, want %d", stats.Count, len(sizes))
+	}
+
+	if float64(stats.Min) != slices.Min(sizes) || float64(stats.Max) != slices.Max(sizes) {
+		t.Errorf("Min/Max = %d/%d, want %.0f/%.0f", stats.Min, stats.Max, slices.Min(sizes), slices.Max(sizes))
+	}
+
+	if math.Abs(stats.Mean-mean) > 1e-6 || math.Abs(stats.StdDev-stddev) > 1e-6 {
+		t.Errorf("Mean/StdDev = %.3f/%.3f, want %.3f/%.3f", stats.Mean, stats.StdDev, mean, stddev)
+	}
+
+	chunker.Reset(bytes.NewReader(nil))
+
+	if stats := chunker.Stats(); stats != (fastcdc.ChunkStats{}) {
+		t.Errorf("Stats after Reset = %+v, want zero", stats)
+	}
+}
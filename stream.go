@@ -0,0 +1,141 @@
+package fastcdc
+
+import (
+	"bytes"
+	"hash"
+)
+
+// StreamChunker is a push-model chunker for data that arrives in pieces of
+// unpredictable size, such as in event-driven network code without an
+// io.Reader. The caller feeds bytes with Feed, pulls completed chunks with Pull
+// and collects the final partial chunk with Finish; leftovers are buffered
+// across feeds. It produces the same chunks as Chunker for the same stream.
+//
+// WithChunkDigest, WithCopyData and WithoutHash apply as for Chunker; the other
+// streaming options do not. A StreamChunker is not safe for concurrent use.
+type StreamChunker struct {
+	core   ChunkerCore
+	cfg    config
+	digest hash.Hash // Chunk content digest (nil unless WithChunkDigest)
+
+	buf     []byte // Fed bytes; buf[start:] is not yet returned in a chunk
+	start   int    // Start of the current chunk in buf
+	scanned int    // Bytes of the current chunk already passed to the core
+	offset  uint64 // Absolute offset of buf[start] in the stream
+}
+
+// NewStreamChunker creates a StreamChunker with the given options.
+func NewStreamChunker(opts ...Option) (*StreamChunker, error) {
+	cfg := defaultConfig()
+	for _, opt := range opts {
+		if err := opt(&cfg); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	s := &StreamChunker{
+		core: newChunkerCoreWithConfig(&cfg),
+		cfg:  cfg,
+	}
+
+	if cfg.digest != nil {
+		s.digest = cfg.digest()
+	}
+
+	return s, nil
+}
+
+// Feed appends p to the stream. p is copied, so the caller may reuse it once
+// Feed returns. Feed invalidates the Data of chunks returned so far, unless
+// WithCopyData is set.
+func (s *StreamChunker) Feed(p []byte) {
+	if s.start > 0 {
+		// Drop the bytes of returned chunks before growing the buffer
+		n := copy(s.buf, s.buf[s.start:])
+		s.buf = s.buf[:n]
+		s.start = 0
+	}
+
+	s.buf = append(s.buf, p...)
+}
+
+// Pull returns the next completed chunk and true, or false if the bytes fed so
+// far do not complete another chunk. Chunk.Data points into the internal
+// buffer and is valid until the next call to Feed. Chunk.Last is never set,
+// since more data may follow; see Finish.
+func (s *StreamChunker) Pull() (Chunk, bool) {
+	boundary, hash, found := s.core.FindBoundary(s.buf[s.start+s.scanned:])
+	if !found {
+		s.scanned = len(s.buf) - s.start
+
+		return Chunk{}, false
+	}
+
+	chunk := s.emit(s.scanned+boundary, hash)
+	s.core.Reset()
+
+	return chunk, true
+}
+
+// Finish ends the stream and returns the bytes fed after the last pulled chunk
+// as the final chunk, with Last set. Call Pull until it returns false first,
+// otherwise those chunks are merged into the final one. If there are no such
+// bytes, Finish returns a zero Chunk; Length tells the two apart. The
+// StreamChunker is then reset for a new stream.
+func (s *StreamChunker) Finish() Chunk {
+	var chunk Chunk
+
+	if n := len(s.buf) - s.start; n > 0 {
+		s.core.FindBoundary(s.buf[s.start+s.scanned:]) // Hash the unscanned bytes
+		chunk = s.emit(n, s.core.Fingerprint())
+		chunk.Last = true
+	}
+
+	s.Reset()
+
+	return chunk
+}
+
+// Reset discards any buffered data and prepares the StreamChunker for a new
+// stream, keeping the internal buffer.
+func (s *StreamChunker) Reset() {
+	s.core.Reset()
+	s.buf = s.buf[:0]
+	s.start = 0
+	s.scanned = 0
+	s.offset = 0
+}
+
+// emit returns the first n bytes of the current chunk as a Chunk and advances past them.
+func (s *StreamChunker) emit(n int, hash uint64) Chunk {
+	chunk := Chunk{
+		Offset: s.offset,
+		Length: uint32(n), //nolint:gosec // G115
+		Hash:   hash,
+		Data:   s.buf[s.start : s.start+n],
+	}
+
+	s.start += n
+	s.scanned = 0
+	s.offset += uint64(n) //nolint:gosec // G115
+
+	if s.cfg.noHash {
+		chunk.Hash = 0
+	}
+
+	if s.cfg.copyData {
+		chunk.Data = bytes.Clone(chunk.Data)
+	}
+
+	if s.digest != nil {
+		s.digest.Reset()
+		s.digest.Write(chunk.Data)
+		chunk.Digest = s.digest.Sum(nil)
+	}
+
+	return chunk
+}
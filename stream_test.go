@@ -0,0 +1,111 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"math/rand"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestStreamChunker(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024+123, 1291)
+	opts := []fastcdc.Option{
+		fastcdc.WithMinSize(4 * 1024),
+		fastcdc.WithTargetSize(16 * 1024),
+		fastcdc.WithChunkDigest(sha256.New),
+	}
+
+	ref, err := fastcdc.NewChunker(bytes.NewReader(data), append(opts, fastcdc.WithCopyData())...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var want []fastcdc.Chunk
+
+	err = ref.Process(func(chunk fastcdc.Chunk) error {
+		want = append(want, chunk)
+
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	s, err := fastcdc.NewStreamChunker(opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rng := rand.New(rand.NewSource(1291)) //nolint:gosec // deterministic test data
+
+	// Run twice to check that Finish resets the stream
+	for range 2 {
+		var got []fastcdc.Chunk
+
+		check := func(chunk fastcdc.Chunk) {
+			i := len(got)
+			if i >= len(want) {
+				t.Fatalf("unexpected chunk %d", i)
+			}
+
+			w := want[i]
+			if chunk.Offset != w.Offset || chunk.Length != w.Length || chunk.Hash != w.Hash ||
+				!bytes.Equal(chunk.Data, w.Data) || !bytes.Equal(chunk.Digest, w.Digest) || chunk.Last != w.Last {
+				t.Fatalf("chunk %d differs: got offset %d length %d, want offset %d length %d",
+					i, chunk.Offset, chunk.Length, w.Offset, w.Length)
+			}
+
+			got = append(got, chunk)
+		}
+
+		for rest := data; len(rest) > 0; {
+			n := min(rng.Intn(64*1024), len(rest))
+			s.Feed(rest[:n])
+			rest = rest[n:]
+
+			for {
+				chunk, ok := s.Pull()
+				if !ok {
+					break
+				}
+
+				check(chunk)
+			}
+		}
+
+		check(s.Finish())
+
+		if len(got) != len(want) {
+			t.Fatalf("got %d chunks, want %d", len(got), len(want))
+		}
+	}
+}
+
+func TestStreamChunkerFinish(t *testing.T) {
+	t.Parallel()
+
+	s, err := fastcdc.NewStreamChunker()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if chunk := s.Finish(); chunk.Length != 0 || chunk.Last {
+		t.Errorf("empty stream: got chunk of %d bytes, want a zero Chunk", chunk.Length)
+	}
+
+	s.Feed([]byte("hello"))
+	s.Feed([]byte(" world"))
+
+	if _, ok := s.Pull(); ok {
+		t.Fatal("Pull returned a chunk shorter than minSize")
+	}
+
+	chunk := s.Finish()
+	if string(chunk.Data) != "hello world" || chunk.Offset != 0 || !chunk.Last {
+		t.Errorf("got final chunk %q at %d (last %v), want %q at 0", chunk.Data, chunk.Offset, chunk.Last, "hello world")
+	}
+}
@@ -72,10 +72,17 @@ var defaultGearTable = [256]uint64{
 	0x671d4bae, 0x00de56e9, 0x1ee489ed, 0x46c3d6f3,
 }
 
-// generateTable creates a new Gear hash table with the given seed.
-// If seed is 0, returns the default table (no allocation).
-// Otherwise, XORs the default table with the seed.
-func generateTable(seed uint64) [256]uint64 {
+// DefaultTable is the Gear hash table used with seed 0, GenerateTable(0).
+// It is a copy for inspection and cross-implementation checks: changing it
+// does not affect chunking.
+//
+//nolint:gochecknoglobals
+var DefaultTable = defaultGearTable
+
+// GenerateTable returns the Gear hash table used with the given seed, as
+// installed by WithSeed. If seed is 0, it returns the default table.
+// Otherwise, each entry is the default table entry XORed with the seed.
+func GenerateTable(seed uint64) [256]uint64 {
 	if seed == 0 {
 		return defaultGearTable
 	}
@@ -87,3 +94,16 @@ func generateTable(seed uint64) [256]uint64 {
 
 	return table
 }
+
+// GearHash returns the Gear fingerprint of data, fp = (fp << 1) + table[b] for
+// each byte from fp = 0, without any boundary logic. Chunk.Hash is the
+// GearHash of the chunk bytes past minSize, or of the whole chunk with
+// WithHashFromStart; only the last 64 bytes affect the result.
+func GearHash(table [256]uint64, data []byte) uint64 {
+	var fp uint64
+	for _, b := range data {
+		fp = (fp << 1) + table[b]
+	}
+
+	return fp
+}
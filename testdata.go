@@ -0,0 +1,25 @@
+package fastcdc
+
+import (
+	"encoding/binary"
+)
+
+// TestData returns n pseudo-random bytes determined by seed, for reproducible
+// tests and benchmarks: the SplitMix64 stream seeded with seed, each output
+// written in little-endian order. It generates several GB/s and its output is
+// frozen, since the golden vectors are defined in terms of it. It is not
+// suitable for anything that needs unpredictable data.
+func TestData(seed uint64, n int) []byte {
+	data := make([]byte, n+7)
+	state := seed
+
+	for i := 0; i < n; i += 8 {
+		state += 0x9e3779b97f4a7c15
+		z := state
+		z = (z ^ (z >> 30)) * 0xbf58476d1ce4e5b9
+		z = (z ^ (z >> 27)) * 0x94d049bb133111eb
+		binary.LittleEndian.PutUint64(data[i:], z^(z>>31))
+	}
+
+	return data[:n:n]
+}
@@ -0,0 +1,39 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestTestData(t *testing.T) {
+	t.Parallel()
+
+	// The first SplitMix64 outputs for seed 0, from the reference implementation
+	want := []uint64{0xe220a8397b1dcdaf, 0x6e789e6aa1b965f4}
+
+	data := fastcdc.TestData(0, 16)
+	for i, w := range want {
+		if got := binary.LittleEndian.Uint64(data[8*i:]); got != w {
+			t.Errorf("output %d = %#x, want %#x", i, got, w)
+		}
+	}
+
+	for _, n := range []int{0, 1, 7, 8, 1001} {
+		got := fastcdc.TestData(42, n)
+		if len(got) != n || cap(got) != n {
+			t.Errorf("TestData(42, %d) has length %d and capacity %d", n, len(got), cap(got))
+		}
+
+		// Shorter outputs are prefixes of longer ones
+		if !bytes.HasPrefix(fastcdc.TestData(42, 2000), got) {
+			t.Errorf("TestData(42, %d) is not a prefix of a longer output", n)
+		}
+	}
+
+	if bytes.Equal(fastcdc.TestData(1, 64), fastcdc.TestData(2, 64)) {
+		t.Error("different seeds produced the same data")
+	}
+}
@@ -0,0 +1,52 @@
+package fastcdc
+
+// findBoundaryScalar is findBoundaryV1 with plain loops instead of 8x unrolled
+// ones and without the parallel lanes, selected with WithUnroll(false).
+// Boundaries are identical.
+func (c *ChunkerCore) findBoundaryScalar(data []byte) (boundary int, hash uint64, found bool) {
+	fp := c.fingerprint
+	start := int(c.position)
+	maskS, matchS, maskL, matchL := c.maskS, c.matchS, c.maskL, c.matchL
+
+	// Skip to minSize without hashing
+	i := min(max(int(c.minSize)-start, 0), len(data))
+	if c.fromStart {
+		fp = c.warmUp(fp, data[:i], start)
+	}
+
+	end := min(int(c.normSize)-start, len(data))
+	for ; i < end; i++ {
+		fp = (fp << 1) + c.table[data[i]]
+		if fp&maskS == matchS {
+			c.fingerprint = fp
+			c.position = 0
+
+			return i + 1, fp, true
+		}
+	}
+
+	end = min(int(c.maxSize)-start, len(data))
+	for ; i < end; i++ {
+		fp = (fp << 1) + c.table[data[i]]
+		if fp&maskL == matchL {
+			c.fingerprint = fp
+			c.position = 0
+
+			return i + 1, fp, true
+		}
+	}
+
+	// Hard limit at maxSize
+	if start+i >= int(c.maxSize) {
+		c.fingerprint = fp
+		c.position = 0
+		c.forced = true
+
+		return i, fp, true
+	}
+
+	c.fingerprint = fp
+	c.position = uint32(start + i) //nolint:gosec // G115
+
+	return i, fp, false
+}
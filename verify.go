@@ -0,0 +1,65 @@
+package fastcdc
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// ErrRoundTripMismatch is returned by VerifyRoundTrip when the chunks do not reproduce the input.
+var ErrRoundTripMismatch = errors.New("chunks do not reproduce the input")
+
+// VerifyRoundTrip chunks r with opts and checks that the chunks are contiguous
+// and that their concatenated data equals the bytes read from r. It returns
+// ErrRoundTripMismatch describing the first discrepancy, or a read error.
+//
+// The input is compared through SHA-256 digests rather than kept in memory, so
+// r may be arbitrarily large.
+func VerifyRoundTrip(r io.Reader, opts ...Option) error {
+	if r == nil {
+		return ErrNilReader
+	}
+
+	input := sha256.New()
+
+	c, err := NewChunker(io.TeeReader(r, input), opts...)
+	if err != nil {
+		return err
+	}
+
+	output := sha256.New()
+
+	var offset uint64
+
+	err = c.Process(func(chunk Chunk) error {
+		data := chunk.Data
+		if c.cfg.guardedData {
+			data = chunk.Guarded.Bytes()
+		}
+
+		if chunk.Offset != offset {
+			return fmt.Errorf("%w: chunk at offset %d, want %d", ErrRoundTripMismatch, chunk.Offset, offset)
+		}
+
+		if int(chunk.Length) != len(data) {
+			return fmt.Errorf("%w: chunk at offset %d has length %d but %d bytes of data",
+				ErrRoundTripMismatch, chunk.Offset, chunk.Length, len(data))
+		}
+
+		output.Write(data)
+		offset += uint64(chunk.Length)
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(input.Sum(nil), output.Sum(nil)) {
+		return fmt.Errorf("%w: concatenated data of %d bytes differs from the input", ErrRoundTripMismatch, offset)
+	}
+
+	return nil
+}
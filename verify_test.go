@@ -0,0 +1,42 @@
+package fastcdc_test
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+func TestVerifyRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(2*1024*1024, 1276)
+
+	tests := []struct {
+		name string
+		opts []fastcdc.Option
+	}{
+		{"default", nil},
+		{"small chunks", []fastcdc.Option{fastcdc.WithMinSize(512), fastcdc.WithTargetSize(2048), fastcdc.WithMaxSize(8192)}},
+		{"guarded data", []fastcdc.Option{fastcdc.WithGuardedData(true)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			if err := fastcdc.VerifyRoundTrip(bytes.NewReader(data), tt.opts...); err != nil {
+				t.Errorf("VerifyRoundTrip failed: %v", err)
+			}
+		})
+	}
+
+	if err := fastcdc.VerifyRoundTrip(bytes.NewReader(nil)); err != nil {
+		t.Errorf("VerifyRoundTrip on empty input failed: %v", err)
+	}
+
+	if err := fastcdc.VerifyRoundTrip(bytes.NewReader(data), fastcdc.WithMinSize(0)); !errors.Is(err, fastcdc.ErrInvalidMinSize) {
+		t.Errorf("Expected ErrInvalidMinSize, got %v", err)
+	}
+}
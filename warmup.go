@@ -0,0 +1,18 @@
+package fastcdc
+
+// gearWindow is the number of bytes a Gear fingerprint depends on: the shift
+// moves each byte's contribution out after 64 steps.
+const gearWindow = 64
+
+// warmUp returns fp updated with the bytes of data that lie in the last
+// gearWindow bytes before minSize, for WithHashFromStart. data starts at
+// position start within the chunk and ends at or before minSize. Hashing only
+// those bytes gives the fingerprint of hashing the chunk from its first byte.
+func (c *ChunkerCore) warmUp(fp uint64, data []byte, start int) uint64 {
+	from := min(max(int(c.minSize)-gearWindow-start, 0), len(data))
+	for _, b := range data[from:] {
+		fp = (fp << 1) + c.table[b]
+	}
+
+	return fp
+}
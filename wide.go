@@ -0,0 +1,44 @@
+package fastcdc
+
+import (
+	"encoding/binary"
+	"sync"
+)
+
+const (
+	// wideSeed seeds the table of the second lane of WithWideFingerprint.
+	wideSeed = 0x57494445 // "WIDE"
+
+	// wideMultiplier is the odd constant the second lane multiplies by after
+	// each byte, the first multiplier of the MurmurHash3 finalizer.
+	wideMultiplier = 0xff51afd7ed558ccd
+)
+
+// wideTable returns the table of the second lane, built on first use from
+// TestData so that it uses all 64 bits, unlike the Gear tables.
+//
+//nolint:gochecknoglobals
+var wideTable = sync.OnceValue(func() *[256]uint64 {
+	var t [256]uint64
+
+	data := TestData(wideSeed, len(t)*8)
+	for i := range t {
+		t[i] = binary.LittleEndian.Uint64(data[i*8:])
+	}
+
+	return &t
+})
+
+// wideLane returns the second lane of the wide fingerprint of a chunk. Unlike
+// the Gear fingerprint, which only depends on the last 64 bytes, every byte of
+// the chunk affects it.
+func wideLane(data []byte) uint64 {
+	table := wideTable()
+
+	var h uint64
+	for _, b := range data {
+		h = (h ^ table[b]) * wideMultiplier
+	}
+
+	return h
+}
@@ -0,0 +1,61 @@
+package fastcdc
+
+// FindBoundaryWindow is like FindBoundary and additionally reports marker, the
+// end offset in data of the last position among the final window bytes before
+// boundary where (fingerprint & maskS) matches the boundary value, or -1 if
+// there is none. Markers form a secondary, content-defined alignment grid inside
+// chunks: they only occur from minSize on, and boundary itself is a marker
+// whenever it is a content-defined boundary, since a maskL match implies a
+// maskS match.
+//
+// Markers are computed by rehashing at most the last window+64 bytes, so
+// FindBoundaryWindow costs about window bytes of hashing more than FindBoundary.
+// With WithHashFromStart the rehash includes the warm-up before minSize. With
+// WithFixedSize no fingerprint is computed and marker is always -1.
+func (c *ChunkerCore) FindBoundaryWindow(data []byte, window int) (boundary int, hash uint64, found bool, marker int) {
+	// State at the start of data, to replay the scan
+	fp, start, rabin := c.fingerprint, int(c.position), c.rabin
+	if start == 0 {
+		rabin = rabinWindow{} // HashRabin starts each chunk from an empty window
+	}
+
+	boundary, hash, found = c.FindBoundary(data)
+	marker = -1
+
+	if window <= 0 || c.fixed {
+		return boundary, hash, found, marker
+	}
+
+	minSize := int(c.minSize)
+
+	hashStart := minSize // First hashed position within the chunk
+	switch {
+	case c.rolling == HashRabin:
+		hashStart = max(minSize-rabinWindowSize, 0)
+	case c.fromStart:
+		hashStart = max(minSize-gearWindow, 0)
+	}
+
+	from := max(boundary-window, 0)
+	i := max(hashStart-start, 0)
+
+	// A fingerprint only depends on the last 64 bytes, so hashing can start
+	// just before the window from an empty state
+	if skip := from - rabinWindowSize; skip > i {
+		i, fp, rabin = skip, 0, rabinWindow{}
+	}
+
+	for ; i < boundary; i++ {
+		if c.rolling == HashRabin {
+			fp = rabin.roll(c.rabinTables, data[i])
+		} else {
+			fp = (fp << 1) + c.table[data[i]]
+		}
+
+		if i >= from && start+i >= minSize && fp&c.maskS == c.matchS {
+			marker = i + 1
+		}
+	}
+
+	return boundary, hash, found, marker
+}
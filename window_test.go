@@ -0,0 +1,189 @@
+package fastcdc_test
+
+import (
+	"testing"
+
+	"github.com/kalbasit/fastcdc"
+)
+
+// referenceMarkers returns, for each chunk of data, its end and the ends of all
+// positions in it where the fingerprint matches maskS.
+func referenceMarkers(data []byte, rabin bool, minSize, maskS uint64, ends []int) [][]int {
+	markers := make([][]int, len(ends))
+	start := 0
+
+	for k, end := range ends {
+		var fp uint64
+
+		for i := start; i < end; i++ {
+			size := uint64(i - start + 1) //nolint:gosec // G115
+			if size <= minSize {
+				continue
+			}
+
+			if rabin {
+				fp = rabinFingerprint(data[max(start, i-63) : i+1])
+			} else {
+				fp = (fp << 1) + fastcdc.DefaultTable[data[i]]
+			}
+
+			if fp&maskS == 0 {
+				markers[k] = append(markers[k], i+1)
+			}
+		}
+
+		start = end
+	}
+
+	return markers
+}
+
+func TestFindBoundaryWindow(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(256*1024, 1296)
+
+	for _, kind := range []fastcdc.RollingHash{fastcdc.HashGear, fastcdc.HashRabin} {
+		opts := []fastcdc.Option{
+			fastcdc.WithRollingHash(kind),
+			fastcdc.WithMinSize(1024),
+			fastcdc.WithTargetSize(4096),
+			fastcdc.WithMaxSize(16 * 1024),
+		}
+
+		ends, err := fastcdc.GoldenBoundaries(data, opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		markers := referenceMarkers(data, kind == fastcdc.HashRabin, 1024, 4096/2-1, ends)
+
+		core, err := fastcdc.NewChunkerCore(opts...)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, window := range []int{0, 100, 1000, 16 * 1024} {
+			core.Reset()
+
+			offset := 0
+
+			for k, end := range ends {
+				boundary, _, _, marker := core.FindBoundaryWindow(data[offset:], window)
+				if offset+boundary != end {
+					t.Fatalf("hash %d, window %d: boundary %d, want %d", kind, window, offset+boundary, end)
+				}
+
+				want := -1
+
+				for _, m := range markers[k] {
+					if m > end-window {
+						want = m - offset
+					}
+				}
+
+				if marker != want {
+					t.Fatalf("hash %d, window %d: chunk %d has marker %d, want %d", kind, window, k, marker, want)
+				}
+
+				offset = end
+
+				core.Reset()
+			}
+		}
+
+		// Chunks continued across calls only report markers in the current data
+		core.Reset()
+
+		const piece, window = 777, 16 * 1024
+
+		chunkStart, k := 0, 0
+
+		for offset := 0; offset < len(data); {
+			n := min(piece, len(data)-offset)
+
+			boundary, _, found, marker := core.FindBoundaryWindow(data[offset:offset+n], window)
+
+			want := -1
+
+			for _, m := range markers[k] {
+				if m > offset && m <= offset+boundary {
+					want = m - offset
+				}
+			}
+
+			if marker != want {
+				t.Fatalf("hash %d, split input at %d: marker %d, want %d (chunk from %d)", kind, offset, marker, want, chunkStart)
+			}
+
+			offset += boundary
+
+			if found {
+				chunkStart = offset
+				k++
+
+				core.Reset()
+			}
+		}
+	}
+}
+
+// TestFindBoundaryWindowOptions verifies markers under options changing how
+// fingerprints are computed: every content-defined boundary is its own marker,
+// and WithFixedSize reports none.
+func TestFindBoundaryWindowOptions(t *testing.T) {
+	t.Parallel()
+
+	data := randBytes(1024*1024, 1324)
+
+	tests := []struct {
+		name    string
+		opts    []fastcdc.Option
+		markers bool // Whether markers are computed at all
+	}{
+		{"HashFromStart", []fastcdc.Option{
+			fastcdc.WithHashFromStart(),
+			fastcdc.WithMinSize(1024),
+			fastcdc.WithTargetSize(4096),
+			fastcdc.WithMaxSize(16 * 1024),
+		}, true},
+		{"FixedSize", []fastcdc.Option{fastcdc.WithFixedSize(4096)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			reference, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			core, err := fastcdc.NewChunkerCore(tt.opts...)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			for offset := 0; offset < len(data); {
+				result, found := reference.FindBoundaryEx(data[offset:])
+				if !found {
+					break
+				}
+
+				boundary, _, _, marker := core.FindBoundaryWindow(data[offset:], 100)
+				if boundary != result.Offset {
+					t.Fatalf("Boundary at %d, want %d", offset+boundary, offset+result.Offset)
+				}
+
+				switch {
+				case !tt.markers && marker != -1:
+					t.Fatalf("Chunk at %d: marker %d, want -1", offset, marker)
+				case tt.markers && result.Reason != fastcdc.MaxLimit && marker != boundary:
+					t.Fatalf("Chunk at %d: marker %d, want the boundary %d", offset, marker, boundary)
+				}
+
+				offset += boundary
+			}
+		})
+	}
+}